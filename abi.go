@@ -0,0 +1,118 @@
+package got
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/gotray/got/internal/env"
+)
+
+// ABIMismatchError reports that the Python runtime found at startup isn't
+// the one the executable was built against
+type ABIMismatchError struct {
+	Want string // version and ABI built against, e.g. "3.13t"
+	Got  string // version and ABI found
+	Path string // libpython or PYTHONHOME found
+}
+
+func (e *ABIMismatchError) Error() string {
+	return fmt.Sprintf("executable was built against Python %s but found Python %s at %s, rebuild it with got build", e.Want, e.Got, e.Path)
+}
+
+// libPythonPattern matches the file names of shared libpython builds,
+// capturing the version and ABI flags
+var libPythonPattern = regexp.MustCompile(`^libpython(\d+\.\d+)([a-z]*)\.(?:so|dylib)`)
+
+// checkABI compares the stamped Python version and ABI with the libpython
+// loaded into the process and the standard library in PYTHONHOME. Executables
+// built without got have nothing to compare with.
+func checkABI(r *Resolution) error {
+	if PythonVersion == "" {
+		return nil
+	}
+	want := majorMinor(PythonVersion) + PythonABI
+	if lib := loadedLibPython(); lib != "" {
+		if m := libPythonPattern.FindStringSubmatch(filepath.Base(lib)); m != nil && m[1]+m[2] != want {
+			return &ABIMismatchError{Want: want, Got: m[1] + m[2], Path: lib}
+		}
+	}
+	home := r.Env["PYTHONHOME"]
+	if home == "" {
+		return nil
+	}
+	if got := homePython(home, want); got != "" && got != want {
+		return &ABIMismatchError{Want: want, Got: got, Path: home}
+	}
+	return nil
+}
+
+// homePython returns the version and ABI of the Python installed in home,
+// or an empty string if it can't tell
+func homePython(home, want string) string {
+	if info, err := env.NewPythonEnv(home).Info(); err == nil && info.MajorMinor != "" {
+		return info.MajorMinor + info.ABI()
+	}
+	if runtime.GOOS == "windows" {
+		return ""
+	}
+	// The standard library directory is named after the version, and the
+	// ABI of free-threaded builds; home may hold several versions
+	stdlib := func(version string) string {
+		name := majorMinor(version)
+		if strings.Contains(version, "t") {
+			name += "t"
+		}
+		return name
+	}
+	if fileExists(filepath.Join(home, "lib", "python"+stdlib(want), "os.py")) {
+		return want
+	}
+	matches, _ := filepath.Glob(filepath.Join(home, "lib", "python3*", "os.py"))
+	if len(matches) != 1 {
+		return ""
+	}
+	return strings.TrimPrefix(filepath.Base(filepath.Dir(matches[0])), "python")
+}
+
+// loadedLibPython returns the path of the shared libpython mapped into the
+// process, or an empty string if there is none or the OS can't tell
+func loadedLibPython() string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	f, err := os.Open("/proc/self/maps")
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		if path := fields[len(fields)-1]; libPythonPattern.MatchString(filepath.Base(path)) {
+			return path
+		}
+	}
+	return ""
+}
+
+// majorMinor returns the major.minor part of a Python version
+func majorMinor(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
+
+func fileExists(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
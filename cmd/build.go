@@ -6,8 +6,11 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"runtime"
 
+	"github.com/gotray/got/cmd/internal/install"
 	"github.com/gotray/got/cmd/internal/rungo"
+	"github.com/gotray/got/internal/env"
 	"github.com/spf13/cobra"
 )
 
@@ -16,7 +19,24 @@ var buildCmd = &cobra.Command{
 	Use:   "build [flags] [package]",
 	Short: "Build a Go package with Python environment configured",
 	Long: func() string {
-		intro := "Build compiles a Go package with the Python environment properly configured.\n\n"
+		intro := `Build compiles a Go package with the Python environment properly configured.
+
+Additional flags:
+  --python-link <mode>     "shared" (default) links libpython with an rpath
+                           into the project, "static" links the static
+                           libpython into the executable, which then needs a
+                           bundled standard library at runtime
+  --direct-cgo-flags       Set CGO_CFLAGS and CGO_LDFLAGS from the Python build
+                           metadata for packages not using pkg-config
+  --check-glibc <version>  After building, fail if the binary or libpython
+                           requires a newer glibc than <version> (e.g. 2.28)
+  --target <os>/<arch>     Cross-compile for a Linux target such as linux/arm64,
+                           linking against the target's Python installed in
+                           .deps/targets
+  --target-cc <command>    C compiler for --target, by default the project's
+                           zig toolchain or <triple>-gcc from PATH
+
+`
 		help, err := rungo.GetGoCommandHelp("build")
 		if err != nil {
 			return intro + "Failed to get go help: " + err.Error()
@@ -25,13 +45,160 @@ var buildCmd = &cobra.Command{
 	}(),
 	DisableFlagParsing: true,
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := rungo.RunCommand("go", append([]string{"build"}, args...)); err != nil {
+		maxGLIBC, args, checkGLIBCVersion := rungo.ExtractFlag(args, "check-glibc")
+		if checkGLIBCVersion && maxGLIBC == "" {
+			fmt.Fprintln(os.Stderr, "Error: --check-glibc requires a version, e.g. --check-glibc 2.28")
+			os.Exit(1)
+		}
+		directCgoFlags, args, err := rungo.ExtractBoolFlag(args, "direct-cgo-flags")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		target, args, hasTarget := rungo.ExtractFlag(args, "target")
+		if hasTarget && target == "" {
+			fmt.Fprintln(os.Stderr, "Error: --target requires GOOS/GOARCH, e.g. linux/arm64")
+			os.Exit(1)
+		}
+		targetCC, args, hasTargetCC := rungo.ExtractFlag(args, "target-cc")
+		if hasTargetCC && (targetCC == "" || target == "") {
+			fmt.Fprintln(os.Stderr, "Error: --target-cc requires a C compiler and --target, e.g. --target linux/arm64 --target-cc aarch64-linux-gnu-gcc")
+			os.Exit(1)
+		}
+		pythonLink, args, err := extractPythonLink(args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		if pythonLink == rungo.PythonLinkStatic && target != "" {
+			fmt.Fprintln(os.Stderr, "Error: --python-link static can't be combined with --target")
+			os.Exit(1)
+		}
+
+		opts := rungo.RunOptions{}
+		if target != "" {
+			if opts, err = targetRunOptions(target, targetCC); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				os.Exit(1)
+			}
+		}
+		if pythonLink == rungo.PythonLinkStatic {
+			if opts, err = staticRunOptions(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				os.Exit(1)
+			}
+		}
+		opts.DirectCgoFlags = directCgoFlags
+		if err := rungo.RunCommandWithOptions("go", append([]string{"build"}, args...), opts); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 			os.Exit(1)
 		}
+		if checkGLIBCVersion {
+			if err := checkBuildGLIBC(args, opts, maxGLIBC); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				os.Exit(1)
+			}
+		}
 	},
 }
 
+// targetRunOptions installs the Python for target and returns the
+// environment cross-compiling for it with the C compiler cc
+func targetRunOptions(target, cc string) (rungo.RunOptions, error) {
+	goos, goarch, err := install.ParseTarget(target)
+	if err != nil {
+		return rungo.RunOptions{}, err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return rungo.RunOptions{}, fmt.Errorf("failed to get working directory: %v", err)
+	}
+	projectRoot, err := rungo.FindProjectRoot(wd)
+	if err != nil {
+		return rungo.RunOptions{}, fmt.Errorf("should run this command in a Got project: %v", err)
+	}
+	if err := install.InstallTarget(projectRoot, goos, goarch, false); err != nil {
+		return rungo.RunOptions{}, err
+	}
+	cc, cxx, err := install.TargetCC(projectRoot, goos, goarch, cc)
+	if err != nil {
+		return rungo.RunOptions{}, err
+	}
+	return rungo.RunOptions{
+		Env:          env.TargetBuildEnv(projectRoot, goos, goarch, cc, cxx),
+		PythonLibDir: env.GetTargetLibDir(projectRoot, goos, goarch),
+		PythonHome:   env.GetTargetPythonRoot(projectRoot, goos, goarch),
+	}, nil
+}
+
+// extractPythonLink removes --python-link from args and validates it
+func extractPythonLink(args []string) (string, []string, error) {
+	pythonLink, args, _ := rungo.ExtractFlag(args, "python-link")
+	switch pythonLink {
+	case "", rungo.PythonLinkShared, rungo.PythonLinkStatic:
+		return pythonLink, args, nil
+	default:
+		return "", nil, fmt.Errorf("invalid --python-link %q, expected shared or static", pythonLink)
+	}
+}
+
+// staticRunOptions prepares linking the project's libpython statically and
+// returns the environment picking up its pkg-config files
+func staticRunOptions() (rungo.RunOptions, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return rungo.RunOptions{}, fmt.Errorf("failed to get working directory: %v", err)
+	}
+	projectRoot, err := rungo.FindProjectRoot(wd)
+	if err != nil {
+		return rungo.RunOptions{}, fmt.Errorf("should run this command in a Got project: %v", err)
+	}
+	pkgConfigDir, err := install.InstallStaticPython(projectRoot, false)
+	if err != nil {
+		return rungo.RunOptions{}, err
+	}
+	// The static pkg-config files shadow the project's shared ones
+	pkgConfigPath := pkgConfigDir + string(os.PathListSeparator) + env.BuildEnv(projectRoot)["PKG_CONFIG_PATH"]
+	return rungo.RunOptions{
+		Env:        map[string]string{"PKG_CONFIG_PATH": pkgConfigPath},
+		PythonLink: rungo.PythonLinkStatic,
+	}, nil
+}
+
+// checkBuildGLIBC checks the glibc requirements of the binary built for args
+// and of the libpython it loads, the project's unless opts name another one.
+// A binary linking Python statically is checked alone.
+func checkBuildGLIBC(args []string, opts rungo.RunOptions, maxGLIBC string) error {
+	goos := os.Getenv("GOOS")
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goos != "linux" {
+		fmt.Fprintf(os.Stderr, "Warning: --check-glibc ignored for %s binaries\n", goos)
+		return nil
+	}
+	output, err := rungo.BuildOutput(args)
+	if err != nil {
+		return fmt.Errorf("failed to determine build output: %v", err)
+	}
+	if opts.PythonLink == rungo.PythonLinkStatic {
+		return checkGLIBC([]string{output}, "", maxGLIBC)
+	}
+	libDir := opts.PythonLibDir
+	if libDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get working directory: %v", err)
+		}
+		projectRoot, err := rungo.FindProjectRoot(wd)
+		if err != nil {
+			return err
+		}
+		libDir = env.GetPythonLayout(projectRoot).LibDir
+	}
+	return checkGLIBC([]string{output}, libDir, maxGLIBC)
+}
+
 func init() {
 	rootCmd.AddCommand(buildCmd)
 }
@@ -0,0 +1,99 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gotray/got/cmd/internal/rungo"
+	"github.com/gotray/got/internal/env"
+	"github.com/spf13/cobra"
+)
+
+// cgoFlagsCmd represents the cgo-flags command
+var cgoFlagsCmd = &cobra.Command{
+	Use:   "cgo-flags [flags]",
+	Short: "Print the cgo and linker flags got builds with",
+	Long: `Cgo-flags prints the flags for embedding the project's Python as shell
+variable assignments:
+
+  CGO_CFLAGS   compiler flags read from the Python build metadata
+  CGO_LDFLAGS  linker flags read from the Python build metadata
+  LDFLAGS      the -ldflags value got passes to go, merged with --ldflags
+
+They allow building with plain go and without pkg-config.
+
+Example:
+  eval "$(got cgo-flags)"
+  go build -ldflags "$LDFLAGS" .
+  got cgo-flags --ldflags "-s -w -X main.version=1.0"`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ldflags, _ := cmd.Flags().GetString("ldflags")
+
+		wd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to get working directory: %v\n", err)
+			os.Exit(1)
+		}
+		projectRoot, err := rungo.FindProjectRoot(wd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: should run this command in a Got project: %v\n", err)
+			os.Exit(1)
+		}
+		if err := printCgoFlags(projectRoot, ldflags); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// printCgoFlags prints the flags for building the project with the user's ldflags
+func printCgoFlags(projectRoot, ldflags string) error {
+	layout := env.GetPythonLayout(projectRoot)
+	goos := os.Getenv("GOOS")
+	flags, err := rungo.PythonCgoFlags(layout, goos)
+	if err != nil {
+		return fmt.Errorf("failed to get Python build flags: %v", err)
+	}
+	var args []string
+	if ldflags != "" {
+		args = []string{"-ldflags", ldflags}
+	}
+	merged, _, err := rungo.MergeLDFlags(args, rungo.LDFlagsOptions{
+		ProjectRoot:  projectRoot,
+		PythonLibDir: layout.LibDir,
+		GOOS:         goos,
+		Stamps:       rungo.BuildStamps(projectRoot, layout),
+	})
+	if err != nil {
+		return err
+	}
+
+	cgoEnv, err := flags.Env()
+	if err != nil {
+		return err
+	}
+	ldflagsValue, err := merged.Value()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("CGO_CFLAGS=%s\n", shellQuote(cgoEnv["CGO_CFLAGS"]))
+	fmt.Printf("CGO_LDFLAGS=%s\n", shellQuote(cgoEnv["CGO_LDFLAGS"]))
+	fmt.Printf("LDFLAGS=%s\n", shellQuote(ldflagsValue))
+	return nil
+}
+
+// shellQuote quotes s as a single POSIX shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func init() {
+	rootCmd.AddCommand(cgoFlagsCmd)
+
+	cgoFlagsCmd.Flags().String("ldflags", "", "Linker flags to merge with the flags got adds")
+}
@@ -0,0 +1,97 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gotray/got/cmd/internal/binutil"
+	"github.com/gotray/got/cmd/internal/rungo"
+	"github.com/gotray/got/internal/env"
+	"github.com/spf13/cobra"
+)
+
+// checkGlibcCmd represents the check-glibc command
+var checkGlibcCmd = &cobra.Command{
+	Use:   "check-glibc [flags] <binary>...",
+	Short: "Report the glibc version required by binaries and libpython",
+	Long: `Check-glibc reads the ELF symbol versions imported by the given binaries and,
+inside a Got project, by libpython*.so in the project's Python lib directory.
+It reports the highest GLIBC_x.y each file requires and fails if it is newer
+than the version given with --max.
+
+Example:
+  got check-glibc ./myapp
+  got check-glibc --max 2.28 ./myapp`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		maxGLIBC, _ := cmd.Flags().GetString("max")
+
+		libDir := ""
+		if wd, err := os.Getwd(); err == nil {
+			if projectRoot, err := rungo.FindProjectRoot(wd); err == nil {
+				libDir = env.GetPythonLayout(projectRoot).LibDir
+			}
+		}
+		if err := checkGLIBC(args, libDir, maxGLIBC); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// checkGLIBC reports the glibc requirements of binaries and of the libpython
+// in libDir, failing if any exceeds maxGLIBC
+func checkGLIBC(binaries []string, libDir, maxGLIBC string) error {
+	if maxGLIBC != "" {
+		if _, err := binutil.ParseGLIBCVersion(maxGLIBC); err != nil {
+			return err
+		}
+	}
+
+	files := append([]string{}, binaries...)
+	if libDir != "" {
+		libs, err := binutil.LibPythonFiles(libDir)
+		if err != nil {
+			return fmt.Errorf("failed to find libpython: %v", err)
+		}
+		files = append(files, libs...)
+	}
+
+	var tooNew []string
+	highest := ""
+	for _, file := range files {
+		req, err := binutil.RequiredGLIBC(file)
+		if err != nil {
+			return err
+		}
+		if req.Version == "" {
+			fmt.Printf("%s: no glibc symbol versions required\n", file)
+			continue
+		}
+		fmt.Printf("%s: requires GLIBC_%s (%s)\n", file, req.Version, strings.Join(req.Symbols, ", "))
+		if highest == "" || binutil.CompareGLIBCVersions(req.Version, highest) > 0 {
+			highest = req.Version
+		}
+		if maxGLIBC != "" && binutil.CompareGLIBCVersions(req.Version, maxGLIBC) > 0 {
+			tooNew = append(tooNew, fmt.Sprintf("%s (GLIBC_%s)", file, req.Version))
+		}
+	}
+
+	if highest != "" {
+		fmt.Printf("Highest required glibc version: %s\n", highest)
+	}
+	if len(tooNew) > 0 {
+		return fmt.Errorf("glibc %s is older than required by %s", maxGLIBC, strings.Join(tooNew, ", "))
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(checkGlibcCmd)
+
+	checkGlibcCmd.Flags().String("max", "", "Fail if a file requires a glibc version newer than this (e.g. 2.28)")
+}
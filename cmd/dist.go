@@ -0,0 +1,112 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gotray/got/cmd/internal/bundle"
+	"github.com/gotray/got/cmd/internal/rungo"
+	"github.com/gotray/got/internal/env"
+	"github.com/spf13/cobra"
+)
+
+// distCmd represents the dist command
+var distCmd = &cobra.Command{
+	Use:   "dist [-o dir] [build flags] [package]",
+	Short: "Build a relocatable application bundle with the Python runtime",
+	Long: `Dist builds a Go package and assembles a self-contained directory that runs
+on machines without the Got project:
+
+  bin/<app>            the executable, finding libraries relative to itself
+  lib/libpython3.x.*   libpython, unless linked with --python-link static
+  lib/python3.x/       the standard library and site-packages
+
+The executable's github.com/gotray/got.SetEnv sets PYTHONHOME to the bundle.
+The directory can be archived and shipped as is.
+
+Flags:
+  -o <dir>              output directory (default "dist")
+  --python-link <mode>  "shared" (default) or "static", see got build
+
+Other flags are passed to go build.
+
+Example:
+  got dist -o out/ .
+  tar czf myapp.tar.gz -C out .`,
+	DisableFlagParsing: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		outDir, args, _ := rungo.ExtractFlag(args, "o")
+		if outDir == "" {
+			outDir = "dist"
+		}
+		pythonLink, args, err := extractPythonLink(args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		if err := dist(outDir, pythonLink, args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// dist builds the package in args into a bundle in outDir
+func dist(outDir, pythonLink string, args []string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %v", err)
+	}
+	projectRoot, err := rungo.FindProjectRoot(wd)
+	if err != nil {
+		return fmt.Errorf("should run this command in a Got project: %v", err)
+	}
+
+	opts := rungo.RunOptions{}
+	if pythonLink == rungo.PythonLinkStatic {
+		if opts, err = staticRunOptions(); err != nil {
+			return err
+		}
+	}
+	opts.Bundle = true
+
+	binDir := bundle.BinDir(outDir)
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", binDir, err)
+	}
+	buildArgs := append([]string{"-o", binDir + string(filepath.Separator)}, args...)
+	if err := rungo.RunCommandWithOptions("go", append([]string{"build"}, buildArgs...), opts); err != nil {
+		return err
+	}
+	binary, err := rungo.BuildOutput(buildArgs)
+	if err != nil {
+		return fmt.Errorf("failed to determine build output: %v", err)
+	}
+
+	layout := env.GetPythonLayout(projectRoot)
+	info, err := layout.Info()
+	if err != nil {
+		return fmt.Errorf("failed to get Python build info: %v", err)
+	}
+	err = bundle.Create(bundle.Options{
+		OutDir:   outDir,
+		Layout:   layout,
+		Info:     info,
+		Binaries: []string{binary},
+		Static:   pythonLink == rungo.PythonLinkStatic,
+		GOOS:     os.Getenv("GOOS"),
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created bundle in %s, run %s\n", outDir, binary)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(distCmd)
+}
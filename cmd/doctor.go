@@ -0,0 +1,73 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/gotray/got/cmd/internal/doctor"
+	"github.com/spf13/cobra"
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the project's Go, Python and C toolchain setup",
+	Long: `Doctor checks the Got project in the current directory: the Go and Python
+installations, the C compiler and pkg-config used by cgo, libpython, and the
+paths recorded in .deps/env.txt. Every problem found comes with a suggested fix.
+
+Example:
+  got doctor
+  got doctor --json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		wd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to get working directory: %v\n", err)
+			os.Exit(1)
+		}
+		report := doctor.Run(wd)
+
+		if asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				os.Exit(1)
+			}
+		} else {
+			printDoctorReport(report)
+		}
+		if report.Failed() {
+			os.Exit(1)
+		}
+	},
+}
+
+// printDoctorReport prints the findings for humans
+func printDoctorReport(report *doctor.Report) {
+	marks := map[doctor.Status]string{
+		doctor.StatusOK:   color.GreenString("✓"),
+		doctor.StatusWarn: color.YellowString("!"),
+		doctor.StatusFail: color.RedString("✗"),
+	}
+	for _, f := range report.Findings {
+		fmt.Printf("%s %-14s %s\n", marks[f.Status], f.Check, f.Message)
+		if f.Fix != "" {
+			fmt.Printf("  %-14s fix: %s\n", "", f.Fix)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().Bool("json", false, "Print the report as JSON")
+}
@@ -57,7 +57,17 @@ Example:
   got init
   got init my-project
   got init --debug my-project
-  got init -v my-project`,
+  got init -v my-project
+  got init --go-version 1.23 --python-version 3.12 my-project
+  got init --python-version latest my-project
+  got init --python-version 3.12 --python-build-date 20241008 my-project
+  got init --python /usr/bin/python3.12 my-project
+  got init --python-from conda:$HOME/miniconda3/envs/ml my-project
+  got init --c-toolchain zig my-project
+  got init --python-profile minimal my-project
+
+Versions accept exact versions, partial versions such as "3.12" or "3.12.x",
+and "latest". Use "got versions" to list the available versions.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Get project path
 		projectPath := "."
@@ -72,7 +82,13 @@ Example:
 		pyVersion, _ := cmd.Flags().GetString("python-version")
 		pyBuildDate, _ := cmd.Flags().GetString("python-build-date")
 		pyFreeThreaded, _ := cmd.Flags().GetBool("python-free-threaded")
-		tinyPkgConfigVersion, _ := cmd.Flags().GetString("tiny-pkg-config-version")
+		pythonPath, _ := cmd.Flags().GetString("python")
+		pythonFrom, _ := cmd.Flags().GetString("python-from")
+		libc, _ := cmd.Flags().GetString("libc")
+		microarch, _ := cmd.Flags().GetString("python-microarch")
+		cToolchain, _ := cmd.Flags().GetString("c-toolchain")
+		zigVersion, _ := cmd.Flags().GetString("zig-version")
+		pythonProfile, _ := cmd.Flags().GetString("python-profile")
 
 		// Check if directory exists
 		if _, err := os.Stat(projectPath); err == nil {
@@ -103,7 +119,22 @@ Example:
 
 		// Install dependencies
 		fmt.Printf("\n%s\n", bold("Installing dependencies..."))
-		if err := install.Dependencies(projectPath, goVersion, tinyPkgConfigVersion, pyVersion, pyBuildDate, pyFreeThreaded, debug, verbose); err != nil {
+		opts := install.Options{
+			GoVersion:     goVersion,
+			PyVersion:     pyVersion,
+			PyBuildDate:   pyBuildDate,
+			FreeThreaded:  pyFreeThreaded,
+			Debug:         debug,
+			Verbose:       verbose,
+			PythonPath:    pythonPath,
+			PythonFrom:    pythonFrom,
+			Libc:          libc,
+			Microarch:     microarch,
+			CToolchain:    cToolchain,
+			ZigVersion:    zigVersion,
+			PythonProfile: pythonProfile,
+		}
+		if err := install.Dependencies(projectPath, opts); err != nil {
 			fmt.Printf("Error installing dependencies: %v\n", err)
 			return
 		}
@@ -119,9 +150,15 @@ func init() {
 	rootCmd.AddCommand(initCmd)
 	initCmd.Flags().Bool("debug", false, "Install debug version of Python (not available on Windows)")
 	initCmd.Flags().BoolP("verbose", "v", false, "Enable verbose output")
-	initCmd.Flags().String("tiny-pkg-config-version", "v0.2.0", "tiny-pkg-config version to install")
-	initCmd.Flags().String("go-version", "1.23.3", "Go version to install")
-	initCmd.Flags().String("python-version", "3.13.0", "Python version to install")
-	initCmd.Flags().String("python-build-date", "20241016", "Python build date")
+	initCmd.Flags().String("go-version", "1.23.3", "Go version to install (exact, partial such as 1.23, or latest)")
+	initCmd.Flags().String("python-version", install.DefaultPythonVersion, "Python version to install (exact, partial such as 3.13, or latest)")
+	initCmd.Flags().String("python-build-date", "", "Python build date (default: the newest build publishing the version)")
 	initCmd.Flags().Bool("python-free-threaded", false, "Install free-threaded version of Python")
+	initCmd.Flags().String("python", "", "Use an existing Python interpreter or prefix instead of downloading one")
+	initCmd.Flags().String("libc", "auto", "C library of the Linux Python build: auto, gnu or musl")
+	initCmd.Flags().String("python-microarch", "v1", "x86-64 level of the linux/amd64 Python build: auto, v1, v2, v3 or v4")
+	initCmd.Flags().String("python-from", "", "Use the Python of another tool, e.g. conda:<env-path>")
+	initCmd.Flags().String("c-toolchain", "host", "C compiler for cgo: host, or zig to install zig cc into .deps (Linux and macOS)")
+	initCmd.Flags().String("zig-version", "0.13.0", "zig version to install for --c-toolchain zig")
+	initCmd.Flags().String("python-profile", "full", "Parts of Python to install: minimal, standard (without tests) or full")
 }
@@ -0,0 +1,112 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gotray/got/cmd/internal/binutil"
+	"github.com/gotray/got/cmd/internal/rungo"
+	"github.com/spf13/cobra"
+)
+
+// inspectCmd represents the inspect command
+var inspectCmd = &cobra.Command{
+	Use:   "inspect <binary>",
+	Short: "Show the got metadata embedded in a binary",
+	Long: `Inspect reads a binary built by got and prints:
+
+  - the github.com/gotray/got variables stamped by got build, such as
+    ProjectRoot and the Python version, ABI and PYTHONPATH
+  - the rpath entries and the libpython the binary links against
+  - the Go build information
+
+Variables are read from the symbol table, or from the recorded -ldflags for
+stripped binaries.
+
+Example:
+  got inspect ./myapp`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := inspect(os.Stdout, args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// inspect prints the got metadata of the binary at path to w
+func inspect(w io.Writer, path string) error {
+	in, err := binutil.Inspect(path)
+	if err != nil {
+		return err
+	}
+	vars := in.Vars
+	if in.BuildInfo != nil {
+		// Binaries without a symbol table still record the -ldflags
+		for _, s := range in.BuildInfo.Settings {
+			if s.Key != "-ldflags" {
+				continue
+			}
+			ldflags, err := rungo.ParseLDFlags(s.Value)
+			if err != nil {
+				continue
+			}
+			for _, x := range ldflags.X {
+				if name, ok := strings.CutPrefix(x.Name, binutil.GotPackage+"."); ok {
+					if _, found := vars[name]; !found {
+						vars[name] = x.Value
+					}
+				}
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "%s: %s\n", path, in.Format)
+	fmt.Fprintf(w, "\ngot variables:\n")
+	if len(vars) == 0 {
+		fmt.Fprintf(w, "  (none, not built by got)\n")
+	}
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-14s %s\n", name, vars[name])
+	}
+
+	fmt.Fprintf(w, "\nlinking:\n")
+	fmt.Fprintf(w, "  %-14s %s\n", "rpath", orNone(in.Rpath))
+	fmt.Fprintf(w, "  %-14s %s\n", "libpython", orNone(in.LibPython))
+
+	if bi := in.BuildInfo; bi != nil {
+		fmt.Fprintf(w, "\nbuild info:\n")
+		fmt.Fprintf(w, "  %-14s %s\n", "go", bi.GoVersion)
+		fmt.Fprintf(w, "  %-14s %s\n", "path", bi.Path)
+		if bi.Main.Path != "" {
+			fmt.Fprintf(w, "  %-14s %s %s\n", "mod", bi.Main.Path, bi.Main.Version)
+		}
+		for _, s := range bi.Settings {
+			fmt.Fprintf(w, "  %-14s %s\n", s.Key, s.Value)
+		}
+	}
+	return nil
+}
+
+// orNone joins values for printing
+func orNone(values []string) string {
+	if len(values) == 0 {
+		return "(none)"
+	}
+	return strings.Join(values, " ")
+}
+
+func init() {
+	rootCmd.AddCommand(inspectCmd)
+}
@@ -0,0 +1,68 @@
+package binutil
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"fmt"
+	"os"
+)
+
+var elfArches = map[elf.Machine]string{
+	elf.EM_X86_64:  "amd64",
+	elf.EM_386:     "386",
+	elf.EM_AARCH64: "arm64",
+	elf.EM_ARM:     "arm",
+	elf.EM_RISCV:   "riscv64",
+	elf.EM_PPC64:   "ppc64le",
+	elf.EM_S390:    "s390x",
+}
+
+var machoArches = map[macho.Cpu]string{
+	macho.CpuAmd64: "amd64",
+	macho.Cpu386:   "386",
+	macho.CpuArm64: "arm64",
+	macho.CpuArm:   "arm",
+}
+
+var peArches = map[uint16]string{
+	pe.IMAGE_FILE_MACHINE_AMD64: "amd64",
+	pe.IMAGE_FILE_MACHINE_I386:  "386",
+	pe.IMAGE_FILE_MACHINE_ARM64: "arm64",
+}
+
+// Format returns the object format of the file at path ("elf", "macho" or
+// "pe") and the GOARCH names of the architectures it contains. Universal
+// Mach-O binaries list every architecture.
+func Format(path string) (string, []string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	if ef, err := elf.NewFile(f); err == nil {
+		return "elf", []string{archName(elfArches[ef.Machine], ef.Machine)}, nil
+	}
+	if mf, err := macho.NewFile(f); err == nil {
+		return "macho", []string{archName(machoArches[mf.Cpu], mf.Cpu)}, nil
+	}
+	if ff, err := macho.NewFatFile(f); err == nil {
+		var arches []string
+		for _, a := range ff.Arches {
+			arches = append(arches, archName(machoArches[a.Cpu], a.Cpu))
+		}
+		return "macho", arches, nil
+	}
+	if pf, err := pe.NewFile(f); err == nil {
+		return "pe", []string{archName(peArches[pf.Machine], pf.Machine)}, nil
+	}
+	return "", nil, fmt.Errorf("%s is not an ELF, Mach-O or PE file", path)
+}
+
+func archName(name string, machine any) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprint(machine)
+}
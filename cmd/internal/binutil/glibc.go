@@ -0,0 +1,127 @@
+package binutil
+
+import (
+	"debug/elf"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const glibcVersionPrefix = "GLIBC_"
+
+// GLIBCRequirement is the newest glibc symbol version an ELF file needs
+type GLIBCRequirement struct {
+	Path    string
+	Version string   // e.g. "2.34", empty if no versioned glibc symbol is imported
+	Symbols []string // symbols requiring Version
+}
+
+// ParseGLIBCVersion parses "2.34" or "GLIBC_2.34" into its numeric components
+func ParseGLIBCVersion(v string) ([]int, error) {
+	v = strings.TrimPrefix(v, glibcVersionPrefix)
+	parts := strings.Split(v, ".")
+	nums := make([]int, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glibc version %q", v)
+		}
+		nums = append(nums, n)
+	}
+	return nums, nil
+}
+
+// CompareGLIBCVersions compares two glibc versions, returning -1, 0 or 1.
+// Unparsable versions sort first.
+func CompareGLIBCVersions(a, b string) int {
+	va, errA := ParseGLIBCVersion(a)
+	vb, errB := ParseGLIBCVersion(b)
+	if errA != nil || errB != nil {
+		switch {
+		case errA != nil && errB != nil:
+			return 0
+		case errA != nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// RequiredGLIBC reads the symbol versions an ELF executable or shared library
+// imports and returns the newest GLIBC_x.y among them. Statically linked
+// files don't import anything and report an empty version.
+func RequiredGLIBC(path string) (*GLIBCRequirement, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ELF file %s: %v", path, err)
+	}
+	defer f.Close()
+
+	req := &GLIBCRequirement{Path: path}
+	symbols, err := f.ImportedSymbols()
+	if err != nil {
+		if f.Section(".dynsym") == nil {
+			return req, nil
+		}
+		return nil, fmt.Errorf("failed to read imported symbols of %s: %v", path, err)
+	}
+
+	for _, sym := range symbols {
+		if !strings.HasPrefix(sym.Version, glibcVersionPrefix) {
+			continue
+		}
+		version := strings.TrimPrefix(sym.Version, glibcVersionPrefix)
+		if _, err := ParseGLIBCVersion(version); err != nil {
+			// e.g. GLIBC_PRIVATE
+			continue
+		}
+		switch c := CompareGLIBCVersions(version, req.Version); {
+		case req.Version == "" || c > 0:
+			req.Version = version
+			req.Symbols = []string{sym.Name}
+		case c == 0:
+			req.Symbols = append(req.Symbols, sym.Name)
+		}
+	}
+	sort.Strings(req.Symbols)
+	return req, nil
+}
+
+// LibPythonFiles returns the shared libpython files in libDir, skipping
+// symlinks to files already listed
+func LibPythonFiles(libDir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(libDir, "libpython*.so*"))
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var files []string
+	for _, match := range matches {
+		real, err := filepath.EvalSymlinks(match)
+		if err != nil || seen[real] {
+			continue
+		}
+		seen[real] = true
+		files = append(files, match)
+	}
+	return files, nil
+}
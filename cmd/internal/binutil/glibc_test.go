@@ -0,0 +1,76 @@
+package binutil
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCompareGLIBCVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.28", "2.28", 0},
+		{"GLIBC_2.28", "2.28", 0},
+		{"2.3", "2.28", -1},
+		{"2.34", "2.28", 1},
+		{"2.2.5", "2.2", 1},
+		{"2.17", "3.0", -1},
+		{"PRIVATE", "2.2.5", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareGLIBCVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareGLIBCVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRequiredGLIBC(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("glibc requirements are only checked on Linux")
+	}
+	if matches, _ := filepath.Glob("/lib/ld-musl-*.so.1"); len(matches) > 0 {
+		t.Skip("system binaries are linked against musl")
+	}
+
+	req, err := RequiredGLIBC("/bin/sh")
+	if err != nil {
+		t.Skipf("cannot read /bin/sh: %v", err)
+	}
+	if req.Version == "" || len(req.Symbols) == 0 {
+		t.Errorf("RequiredGLIBC(/bin/sh) = %+v, want a glibc version and symbols", req)
+	}
+
+	notELF := filepath.Join(t.TempDir(), "script")
+	if err := os.WriteFile(notELF, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RequiredGLIBC(notELF); err == nil {
+		t.Error("RequiredGLIBC() error = nil for a non-ELF file")
+	}
+}
+
+func TestLibPythonFiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on Windows")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "libpython3.12.so.1.0"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("libpython3.12.so.1.0", filepath.Join(dir, "libpython3.so")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "libssl.so"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	files, err := LibPythonFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("LibPythonFiles() = %v, want a single libpython", files)
+	}
+}
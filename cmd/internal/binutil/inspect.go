@@ -0,0 +1,221 @@
+package binutil
+
+import (
+	"debug/buildinfo"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// GotPackage is the package of the variables got build stamps into executables
+const GotPackage = "github.com/gotray/got"
+
+// Inspection describes what got build recorded in an executable
+type Inspection struct {
+	Format    string               // "elf", "macho" or "pe"
+	Vars      map[string]string    // string variables of GotPackage by name, from the symbol table
+	Rpath     []string             // DT_RUNPATH and DT_RPATH entries, or LC_RPATH on macOS
+	LibPython []string             // libpython the executable links against
+	BuildInfo *buildinfo.BuildInfo // Go build information, nil if there is none
+}
+
+// Inspect reads the got metadata, the library search paths and the Go build
+// information of the executable at path. Stripped executables have no
+// symbol table to read variables from.
+func Inspect(path string) (*Inspection, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var in *Inspection
+	if ef, err := elf.NewFile(f); err == nil {
+		in, err = inspectELF(ef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+	} else if mf, err := macho.NewFile(f); err == nil {
+		in = inspectMachO(mf)
+	} else if pf, err := pe.NewFile(f); err == nil {
+		in = inspectPE(pf)
+	} else {
+		return nil, fmt.Errorf("%s is not an ELF, Mach-O or PE file", path)
+	}
+	if bi, err := buildinfo.Read(f); err == nil {
+		in.BuildInfo = bi
+	}
+	return in, nil
+}
+
+func inspectELF(f *elf.File) (*Inspection, error) {
+	in := &Inspection{Format: "elf", Vars: map[string]string{}}
+	for _, tag := range []elf.DynTag{elf.DT_RUNPATH, elf.DT_RPATH} {
+		values, err := f.DynString(tag)
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			in.Rpath = append(in.Rpath, strings.Split(value, ":")...)
+		}
+	}
+	libs, err := f.ImportedLibraries()
+	if err != nil {
+		return nil, err
+	}
+	in.LibPython = filterLibPython(libs)
+
+	// Stripped executables have no symbol table
+	symbols, _ := f.Symbols()
+	var sections []memSection
+	for _, s := range f.Sections {
+		if s.Type == elf.SHT_PROGBITS && s.Addr != 0 {
+			sections = append(sections, memSection{addr: s.Addr, size: s.Size, read: s.Data})
+		}
+	}
+	ptrSize := 4
+	if f.Class == elf.ELFCLASS64 {
+		ptrSize = 8
+	}
+	mem := &memory{sections: sections, ptrSize: ptrSize, order: f.ByteOrder}
+	for _, sym := range symbols {
+		readVar(in.Vars, mem, sym.Name, sym.Value)
+	}
+	return in, nil
+}
+
+func inspectMachO(f *macho.File) *Inspection {
+	in := &Inspection{Format: "macho", Vars: map[string]string{}}
+	for _, load := range f.Loads {
+		if rpath, ok := load.(*macho.Rpath); ok {
+			in.Rpath = append(in.Rpath, rpath.Path)
+		}
+	}
+	libs, _ := f.ImportedLibraries()
+	in.LibPython = filterLibPython(libs)
+
+	if f.Symtab == nil {
+		return in
+	}
+	var sections []memSection
+	for _, s := range f.Sections {
+		if s.Addr != 0 && s.Offset != 0 {
+			sections = append(sections, memSection{addr: s.Addr, size: s.Size, read: s.Data})
+		}
+	}
+	ptrSize := 4
+	if f.Magic == macho.Magic64 {
+		ptrSize = 8
+	}
+	mem := &memory{sections: sections, ptrSize: ptrSize, order: f.ByteOrder}
+	for _, sym := range f.Symtab.Syms {
+		// External linking prefixes C-visible names with an underscore
+		readVar(in.Vars, mem, strings.TrimPrefix(sym.Name, "_"), sym.Value)
+	}
+	return in
+}
+
+func inspectPE(f *pe.File) *Inspection {
+	in := &Inspection{Format: "pe", Vars: map[string]string{}}
+	libs, _ := f.ImportedLibraries()
+	in.LibPython = filterLibPython(libs)
+	return in
+}
+
+// filterLibPython returns the libpython entries of libs
+func filterLibPython(libs []string) []string {
+	var result []string
+	for _, lib := range libs {
+		base := strings.ToLower(lib[strings.LastIndexAny(lib, `/\`)+1:])
+		if strings.HasPrefix(base, "libpython") || (strings.HasPrefix(base, "python3") && strings.HasSuffix(base, ".dll")) {
+			result = append(result, lib)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// readVar records the string variable of GotPackage at addr
+func readVar(vars map[string]string, mem *memory, name string, addr uint64) {
+	varName, ok := strings.CutPrefix(name, GotPackage+".")
+	if !ok || strings.ContainsAny(varName, ".·") {
+		return
+	}
+	if value, ok := mem.readString(addr); ok {
+		vars[varName] = value
+	}
+}
+
+// memSection is a section of an executable loaded at addr
+type memSection struct {
+	addr, size uint64
+	read       func() ([]byte, error)
+	data       []byte
+}
+
+// memory reads values from the sections of an executable by address
+type memory struct {
+	sections []memSection
+	ptrSize  int
+	order    binary.ByteOrder
+}
+
+// read returns n bytes at addr
+func (m *memory) read(addr uint64, n int) ([]byte, bool) {
+	for i := range m.sections {
+		s := &m.sections[i]
+		if addr < s.addr || addr+uint64(n) > s.addr+s.size {
+			continue
+		}
+		if s.data == nil {
+			data, err := s.read()
+			if err != nil {
+				return nil, false
+			}
+			s.data = data
+		}
+		off := addr - s.addr
+		if off+uint64(n) > uint64(len(s.data)) {
+			return nil, false
+		}
+		return s.data[off : off+uint64(n)], true
+	}
+	return nil, false
+}
+
+// readPtr reads a pointer-sized word at addr
+func (m *memory) readPtr(addr uint64) (uint64, bool) {
+	b, ok := m.read(addr, m.ptrSize)
+	if !ok {
+		return 0, false
+	}
+	if m.ptrSize == 8 {
+		return m.order.Uint64(b), true
+	}
+	return uint64(m.order.Uint32(b)), true
+}
+
+// readString reads the Go string whose header is at addr
+func (m *memory) readString(addr uint64) (string, bool) {
+	ptr, ok := m.readPtr(addr)
+	if !ok {
+		return "", false
+	}
+	n, ok := m.readPtr(addr + uint64(m.ptrSize))
+	if !ok || n > 1<<20 {
+		return "", false
+	}
+	if n == 0 {
+		return "", true
+	}
+	b, ok := m.read(ptr, int(n))
+	if !ok {
+		return "", false
+	}
+	return string(b), true
+}
@@ -0,0 +1,63 @@
+package binutil
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a Go program")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":      "module " + GotPackage + "\n\ngo 1.21\n",
+		"got.go":      "package got\n\nvar ProjectRoot, PythonVersion string\n",
+		"app/main.go": "package main\n\nimport \"" + GotPackage + "\"\n\nfunc main() { println(got.ProjectRoot, got.PythonVersion) }\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exe := filepath.Join(dir, "app.exe")
+	// -trimpath leaves -ldflags out of the build information, so the
+	// variables can only come from the symbol table
+	cmd := exec.Command("go", "build", "-trimpath", "-o", exe,
+		"-ldflags", "-X "+GotPackage+".ProjectRoot=/proj -X "+GotPackage+".PythonVersion=3.13.1", "./app")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOFLAGS=-mod=mod")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("go build failed: %v\n%s", err, out)
+	}
+
+	in, err := Inspect(exe)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	want := map[string]string{"ProjectRoot": "/proj", "PythonVersion": "3.13.1"}
+	if !reflect.DeepEqual(in.Vars, want) {
+		t.Errorf("Inspect() Vars = %v, want %v", in.Vars, want)
+	}
+	if in.BuildInfo == nil || in.BuildInfo.Path != GotPackage+"/app" {
+		t.Errorf("Inspect() BuildInfo = %v, want the build information of %s/app", in.BuildInfo, GotPackage)
+	}
+	if len(in.LibPython) != 0 {
+		t.Errorf("Inspect() LibPython = %q, want none", in.LibPython)
+	}
+}
+
+func TestFilterLibPython(t *testing.T) {
+	libs := []string{"libc.so.6", "libpython3.13t.so.1.0", "@rpath/libpython3.12.dylib", "KERNEL32.dll", "python313.dll", "python3.dll"}
+	want := []string{"@rpath/libpython3.12.dylib", "libpython3.13t.so.1.0", "python3.dll", "python313.dll"}
+	if got := filterLibPython(libs); !reflect.DeepEqual(got, want) {
+		t.Errorf("filterLibPython() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,410 @@
+package binutil
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// PatchOptions describes the changes PatchELF makes to the dynamic section
+type PatchOptions struct {
+	Rpath         string            // new DT_RUNPATH (or existing DT_RPATH), unchanged if empty
+	ReplaceNeeded map[string]string // DT_NEEDED libraries to replace by name
+}
+
+// PatchELF rewrites the rpath and needed libraries of the dynamically linked
+// ELF executable or library at path, like patchelf.
+//
+// The dynamic string table and dynamic section are copied with the changes to
+// a new PT_LOAD segment at the end of the file, so existing strings keep their
+// offsets. The program header of a PT_NOTE segment, which the loader doesn't
+// need, is reused for it; files patched before reuse the segment added then.
+func PatchELF(path string, opts PatchOptions) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	patched, err := patchELF(data, opts)
+	if err != nil {
+		return fmt.Errorf("failed to patch %s: %v", path, err)
+	}
+	return writeFileAtomic(path, patched)
+}
+
+// elfFile gives access to the raw headers of an ELF file
+type elfFile struct {
+	*elf.File
+	data    []byte
+	order   binary.ByteOrder
+	is64    bool
+	ptrSize uint64
+}
+
+// dynEntry is an entry of the dynamic section
+type dynEntry struct {
+	tag elf.DynTag
+	val uint64
+}
+
+func patchELF(data []byte, opts PatchOptions) ([]byte, error) {
+	f, err := elf.NewFile(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	ef := &elfFile{File: f, data: data, order: f.ByteOrder, is64: f.Class == elf.ELFCLASS64, ptrSize: 4}
+	if ef.is64 {
+		ef.ptrSize = 8
+	}
+	if f.Type != elf.ET_EXEC && f.Type != elf.ET_DYN {
+		return nil, fmt.Errorf("not an executable or shared library")
+	}
+
+	progs := make([]elf.ProgHeader, len(f.Progs))
+	dynIndex := -1
+	for i, p := range f.Progs {
+		progs[i] = p.ProgHeader
+		if p.Type == elf.PT_DYNAMIC {
+			dynIndex = i
+		}
+	}
+	if dynIndex < 0 {
+		return nil, fmt.Errorf("no dynamic section, the file is statically linked")
+	}
+	dynProg := progs[dynIndex]
+	entries := ef.readDynamic(dynProg.Off, dynProg.Filesz)
+
+	strtab, strsz := ef.dynValue(entries, elf.DT_STRTAB), ef.dynValue(entries, elf.DT_STRSZ)
+	strOff, ok := vaddrToOffset(progs, strtab)
+	if !ok || strOff+strsz > uint64(len(data)) {
+		return nil, fmt.Errorf("invalid DT_STRTAB")
+	}
+	dynstr := append([]byte{}, data[strOff:strOff+strsz]...)
+	addString := func(s string) uint64 {
+		off := uint64(len(dynstr))
+		dynstr = append(append(dynstr, s...), 0)
+		return off
+	}
+
+	// Replace the needed libraries, keeping the old strings for other users
+	replaced := map[string]uint64{}
+	for i, e := range entries {
+		if e.tag != elf.DT_NEEDED {
+			continue
+		}
+		name := cString(dynstr, e.val)
+		if newName, ok := opts.ReplaceNeeded[name]; ok {
+			entries[i].val = addString(newName)
+			replaced[name] = entries[i].val
+		}
+	}
+	var missing []string
+	for name := range opts.ReplaceNeeded {
+		if _, ok := replaced[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("no DT_NEEDED entry for %q", missing)
+	}
+	if opts.Rpath != "" {
+		rpathIndex := -1
+		for i, e := range entries {
+			if e.tag == elf.DT_RUNPATH || (e.tag == elf.DT_RPATH && rpathIndex < 0) {
+				rpathIndex = i
+			}
+		}
+		if rpathIndex < 0 {
+			entries = append(entries, dynEntry{tag: elf.DT_RUNPATH})
+			rpathIndex = len(entries) - 1
+		}
+		entries[rpathIndex].val = addString(opts.Rpath)
+	}
+
+	// Lay out the new segment: the string table followed by the dynamic section
+	loadIndex, reuse := ef.findPatchSegment(progs, strOff, dynProg)
+	if loadIndex < 0 {
+		return nil, fmt.Errorf("no PT_NOTE program header to reuse for the new segment")
+	}
+	align := uint64(0x1000)
+	var end uint64
+	for _, p := range progs {
+		if p.Type == elf.PT_LOAD {
+			align = max(align, p.Align)
+			if !reuse || p != progs[loadIndex] {
+				end = max(end, p.Vaddr+p.Memsz)
+			}
+		}
+	}
+	out := data
+	var segOff, segVaddr uint64
+	if reuse {
+		segOff, segVaddr = progs[loadIndex].Off, progs[loadIndex].Vaddr
+		out = data[:segOff:segOff]
+	} else {
+		segOff = alignUp(uint64(len(data)), 16)
+		segVaddr = alignUp(end, align) + segOff%align
+		out = append(data[:len(data):len(data)], make([]byte, segOff-uint64(len(data)))...)
+	}
+	dynOff := alignUp(uint64(len(dynstr)), ef.ptrSize)
+	for i := range entries {
+		switch entries[i].tag {
+		case elf.DT_STRTAB:
+			entries[i].val = segVaddr
+		case elf.DT_STRSZ:
+			entries[i].val = uint64(len(dynstr))
+		}
+	}
+	dynamic := ef.encodeDynamic(append(entries, dynEntry{tag: elf.DT_NULL}))
+	segment := append(append(dynstr, make([]byte, dynOff-uint64(len(dynstr)))...), dynamic...)
+	out = append(out, segment...)
+
+	// Point the versions needed from replaced libraries at their new names
+	if err := ef.patchVerneed(out, progs, entries, dynstr, replaced); err != nil {
+		return nil, err
+	}
+
+	load := elf.ProgHeader{
+		Type:   elf.PT_LOAD,
+		Flags:  elf.PF_R | elf.PF_W, // the loader writes DT_DEBUG
+		Off:    segOff,
+		Vaddr:  segVaddr,
+		Paddr:  segVaddr,
+		Filesz: uint64(len(segment)),
+		Memsz:  uint64(len(segment)),
+		Align:  align,
+	}
+	progs[dynIndex].Off = segOff + dynOff
+	progs[dynIndex].Vaddr = segVaddr + dynOff
+	progs[dynIndex].Paddr = segVaddr + dynOff
+	progs[dynIndex].Filesz = uint64(len(dynamic))
+	progs[dynIndex].Memsz = uint64(len(dynamic))
+	if reuse {
+		progs[loadIndex] = load
+	} else {
+		progs = insertLoad(progs, loadIndex, load)
+	}
+	ef.writeProgs(out, progs)
+	ef.updateSections(out, map[string][3]uint64{
+		".dynstr":  {segVaddr, segOff, uint64(len(dynstr))},
+		".dynamic": {segVaddr + dynOff, segOff + dynOff, uint64(len(dynamic))},
+	})
+	return out, nil
+}
+
+// findPatchSegment returns the index of the program header for the new
+// segment and whether it is the segment added by an earlier patch, which
+// holds the dynamic string table at its start and ends the file
+func (ef *elfFile) findPatchSegment(progs []elf.ProgHeader, strOff uint64, dynProg elf.ProgHeader) (int, bool) {
+	note := -1
+	for i, p := range progs {
+		switch p.Type {
+		case elf.PT_LOAD:
+			if p.Off == strOff && p.Off+p.Filesz == uint64(len(ef.data)) && dynProg.Off > p.Off {
+				return i, true
+			}
+		case elf.PT_NOTE:
+			note = i
+		}
+	}
+	return note, false
+}
+
+// insertLoad replaces the program header at index with load, moving it after
+// the last PT_LOAD since the loader expects them in ascending address order
+func insertLoad(progs []elf.ProgHeader, index int, load elf.ProgHeader) []elf.ProgHeader {
+	progs = append(progs[:index:index], progs[index+1:]...)
+	last := -1
+	for i, p := range progs {
+		if p.Type == elf.PT_LOAD {
+			last = i
+		}
+	}
+	result := append([]elf.ProgHeader{}, progs[:last+1]...)
+	result = append(result, load)
+	return append(result, progs[last+1:]...)
+}
+
+// patchVerneed renames the files of version requirements on replaced libraries
+func (ef *elfFile) patchVerneed(out []byte, progs []elf.ProgHeader, entries []dynEntry, dynstr []byte, replaced map[string]uint64) error {
+	vaddr, count := ef.dynValue(entries, elf.DT_VERNEED), ef.dynValue(entries, elf.DT_VERNEEDNUM)
+	if len(replaced) == 0 || vaddr == 0 {
+		return nil
+	}
+	off, ok := vaddrToOffset(progs, vaddr)
+	if !ok {
+		return fmt.Errorf("invalid DT_VERNEED")
+	}
+	for i := uint64(0); i < count; i++ {
+		if off+16 > uint64(len(out)) {
+			return fmt.Errorf("invalid DT_VERNEED")
+		}
+		file := uint64(ef.order.Uint32(out[off+4:]))
+		if newOff, ok := replaced[cString(dynstr, file)]; ok {
+			ef.order.PutUint32(out[off+4:], uint32(newOff))
+		}
+		next := uint64(ef.order.Uint32(out[off+12:]))
+		if next == 0 {
+			break
+		}
+		off += next
+	}
+	return nil
+}
+
+// readDynamic returns the entries of the dynamic section at off up to DT_NULL
+func (ef *elfFile) readDynamic(off, size uint64) []dynEntry {
+	var entries []dynEntry
+	entSize := 2 * ef.ptrSize
+	for p := off; p+entSize <= off+size && p+entSize <= uint64(len(ef.data)); p += entSize {
+		var e dynEntry
+		if ef.is64 {
+			e = dynEntry{elf.DynTag(ef.order.Uint64(ef.data[p:])), ef.order.Uint64(ef.data[p+8:])}
+		} else {
+			e = dynEntry{elf.DynTag(int32(ef.order.Uint32(ef.data[p:]))), uint64(ef.order.Uint32(ef.data[p+4:]))}
+		}
+		if e.tag == elf.DT_NULL {
+			break
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func (ef *elfFile) encodeDynamic(entries []dynEntry) []byte {
+	b := make([]byte, uint64(len(entries))*2*ef.ptrSize)
+	for i, e := range entries {
+		p := uint64(i) * 2 * ef.ptrSize
+		if ef.is64 {
+			ef.order.PutUint64(b[p:], uint64(e.tag))
+			ef.order.PutUint64(b[p+8:], e.val)
+		} else {
+			ef.order.PutUint32(b[p:], uint32(e.tag))
+			ef.order.PutUint32(b[p+4:], uint32(e.val))
+		}
+	}
+	return b
+}
+
+// dynValue returns the value of the first entry with tag, or 0
+func (ef *elfFile) dynValue(entries []dynEntry, tag elf.DynTag) uint64 {
+	for _, e := range entries {
+		if e.tag == tag {
+			return e.val
+		}
+	}
+	return 0
+}
+
+// writeProgs writes the program headers into out
+func (ef *elfFile) writeProgs(out []byte, progs []elf.ProgHeader) {
+	phoff, phentsize := ef.header()
+	for i, p := range progs {
+		b := out[phoff+uint64(i)*phentsize:]
+		if ef.is64 {
+			ef.order.PutUint32(b[0:], uint32(p.Type))
+			ef.order.PutUint32(b[4:], uint32(p.Flags))
+			for j, v := range []uint64{p.Off, p.Vaddr, p.Paddr, p.Filesz, p.Memsz, p.Align} {
+				ef.order.PutUint64(b[8+8*j:], v)
+			}
+		} else {
+			for j, v := range []uint64{uint64(p.Type), p.Off, p.Vaddr, p.Paddr, p.Filesz, p.Memsz, uint64(p.Flags), p.Align} {
+				ef.order.PutUint32(b[4*j:], uint32(v))
+			}
+		}
+	}
+}
+
+// updateSections sets the address, offset and size of the named section
+// headers, which tools such as readelf read the dynamic section through
+func (ef *elfFile) updateSections(out []byte, sections map[string][3]uint64) {
+	shoff, shentsize := ef.sectionHeader()
+	if shoff == 0 {
+		return
+	}
+	for i, s := range ef.Sections {
+		v, ok := sections[s.Name]
+		if !ok {
+			continue
+		}
+		b := out[shoff+uint64(i)*shentsize:]
+		if ef.is64 {
+			ef.order.PutUint64(b[16:], v[0])
+			ef.order.PutUint64(b[24:], v[1])
+			ef.order.PutUint64(b[32:], v[2])
+		} else {
+			ef.order.PutUint32(b[12:], uint32(v[0]))
+			ef.order.PutUint32(b[16:], uint32(v[1]))
+			ef.order.PutUint32(b[20:], uint32(v[2]))
+		}
+	}
+}
+
+// header returns e_phoff and e_phentsize
+func (ef *elfFile) header() (uint64, uint64) {
+	if ef.is64 {
+		return ef.order.Uint64(ef.data[32:]), uint64(ef.order.Uint16(ef.data[54:]))
+	}
+	return uint64(ef.order.Uint32(ef.data[28:])), uint64(ef.order.Uint16(ef.data[42:]))
+}
+
+// sectionHeader returns e_shoff and e_shentsize
+func (ef *elfFile) sectionHeader() (uint64, uint64) {
+	if ef.is64 {
+		return ef.order.Uint64(ef.data[40:]), uint64(ef.order.Uint16(ef.data[58:]))
+	}
+	return uint64(ef.order.Uint32(ef.data[32:])), uint64(ef.order.Uint16(ef.data[46:]))
+}
+
+// vaddrToOffset converts a virtual address to a file offset
+func vaddrToOffset(progs []elf.ProgHeader, vaddr uint64) (uint64, bool) {
+	for _, p := range progs {
+		if p.Type == elf.PT_LOAD && vaddr >= p.Vaddr && vaddr < p.Vaddr+p.Filesz {
+			return vaddr - p.Vaddr + p.Off, true
+		}
+	}
+	return 0, false
+}
+
+// cString returns the NUL-terminated string at off in b
+func cString(b []byte, off uint64) string {
+	if off >= uint64(len(b)) {
+		return ""
+	}
+	if i := bytes.IndexByte(b[off:], 0); i >= 0 {
+		return string(b[off : off+uint64(i)])
+	}
+	return string(b[off:])
+}
+
+func alignUp(v, align uint64) uint64 {
+	return (v + align - 1) / align * align
+}
+
+// writeFileAtomic replaces the file at path with data, keeping its mode
+func writeFileAtomic(path string, data []byte) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), fi.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
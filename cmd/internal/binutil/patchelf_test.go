@@ -0,0 +1,118 @@
+package binutil
+
+import (
+	"debug/elf"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// buildPatchTest builds an executable in dir linking against libfoo.so, and
+// libfoo.so and libbar.so with versioned symbols in dir/lib
+func buildPatchTest(t *testing.T, dir string, buildmode string) string {
+	t.Helper()
+	files := map[string]string{
+		"go.mod":  "module patchtest\n\ngo 1.21\n",
+		"main.go": "package main\n\n/*\n#cgo LDFLAGS: -L${SRCDIR}/lib -lfoo\nconst char *name(void);\n*/\nimport \"C\"\n\nfunc main() { println(C.GoString(C.name())) }\n",
+		"c/foo.c": "const char *name(void) { return LIBNAME; }\n",
+		"c/map":   "FOO_1.0 { global: name; local: *; };\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "lib"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, lib := range []string{"foo", "bar"} {
+		out, err := exec.Command("gcc", "-shared", "-fPIC", `-DLIBNAME="`+lib+`"`, "-Wl,--version-script="+filepath.Join(dir, "c", "map"),
+			"-o", filepath.Join(dir, "lib", "lib"+lib+".so"), filepath.Join(dir, "c", "foo.c")).CombinedOutput()
+		if err != nil {
+			t.Skipf("gcc failed: %v\n%s", err, out)
+		}
+	}
+	exe := filepath.Join(dir, "app-"+buildmode)
+	cmd := exec.Command("go", "build", "-buildmode="+buildmode, "-o", exe, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=1", "GOFLAGS=-mod=mod")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("go build failed: %v\n%s", err, out)
+	}
+	return exe
+}
+
+func TestPatchELF(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("patched executables only run on Linux")
+	}
+	if testing.Short() {
+		t.Skip("builds a cgo program")
+	}
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("gcc not found")
+	}
+	dir := t.TempDir()
+	for _, buildmode := range []string{"exe", "pie"} {
+		t.Run(buildmode, func(t *testing.T) {
+			exe := buildPatchTest(t, dir, buildmode)
+			run := func() string {
+				out, err := exec.Command(exe).CombinedOutput()
+				if err != nil {
+					return "error: " + strings.TrimSpace(string(out))
+				}
+				return strings.TrimSpace(string(out))
+			}
+			if got := run(); !strings.HasPrefix(got, "error: ") {
+				t.Fatalf("unpatched executable output = %q, want a loader error", got)
+			}
+
+			steps := []struct {
+				opts       PatchOptions
+				wantOutput string
+				wantRpath  []string
+				wantNeeded string
+			}{
+				{PatchOptions{Rpath: "$ORIGIN/lib"}, "foo", []string{"$ORIGIN/lib"}, "libfoo.so"},
+				// The second patch reuses the segment added by the first
+				{
+					PatchOptions{Rpath: "/nonexistent:$ORIGIN/lib", ReplaceNeeded: map[string]string{"libfoo.so": "libbar.so"}},
+					"bar", []string{"/nonexistent:$ORIGIN/lib"}, "libbar.so",
+				},
+			}
+			for i, step := range steps {
+				if err := PatchELF(exe, step.opts); err != nil {
+					t.Fatalf("step %d: PatchELF() error = %v", i, err)
+				}
+				if got := run(); got != step.wantOutput {
+					t.Errorf("step %d: output = %q, want %q", i, got, step.wantOutput)
+				}
+				f, err := elf.Open(exe)
+				if err != nil {
+					t.Fatal(err)
+				}
+				rpath, _ := f.DynString(elf.DT_RUNPATH)
+				needed, _ := f.ImportedLibraries()
+				f.Close()
+				if !reflect.DeepEqual(rpath, step.wantRpath) {
+					t.Errorf("step %d: DT_RUNPATH = %q, want %q", i, rpath, step.wantRpath)
+				}
+				if len(needed) == 0 || needed[0] != step.wantNeeded {
+					t.Errorf("step %d: DT_NEEDED = %q, want %s first", i, needed, step.wantNeeded)
+				}
+			}
+
+			if err := PatchELF(exe, PatchOptions{ReplaceNeeded: map[string]string{"libmissing.so": "libbar.so"}}); err == nil {
+				t.Error("PatchELF() replacing a library not needed error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+// Package bundle assembles relocatable application directories containing
+// executables built by got and the Python runtime they embed.
+//
+// A bundle is laid out like a Python installation so that its root directory
+// serves as PYTHONHOME:
+//
+//	got-bundle.json       marker describing the bundled Python
+//	bin/app               executables, with an rpath to ../lib
+//	lib/libpython3.x.so   shared libpython, unless linked statically
+//	lib/python3.x/        standard library, lib-dynload and site-packages
+//
+// Windows bundles follow the layout of Windows Python installations instead,
+// with Lib/ and DLLs/ in the root and the Python DLLs next to the executables.
+package bundle
+
+import (
+	"debug/macho"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/gotray/got/internal/env"
+)
+
+// Options describes a bundle to create
+type Options struct {
+	OutDir   string           // bundle root directory
+	Layout   env.PythonLayout // Python installation the executables were built against
+	Info     *env.PythonInfo  // build of that installation
+	Binaries []string         // executables in OutDir/bin
+	Static   bool             // libpython is linked into the executables
+	GOOS     string           // OS the executables were built for, defaults to the host
+}
+
+// RootFromBin is the bundle root relative to the directory of the executables
+const RootFromBin = ".."
+
+// BinDir returns the directory of the executables in a bundle
+func BinDir(outDir string) string {
+	return filepath.Join(outDir, "bin")
+}
+
+// Rpath returns the runtime search path of executables in a bundle for goos,
+// or an empty string where executables find libraries without one
+func Rpath(goos string) string {
+	switch goos {
+	case "windows":
+		return ""
+	case "darwin":
+		return "@executable_path/../lib"
+	default:
+		return "$ORIGIN/../lib"
+	}
+}
+
+// Create copies the Python runtime into the bundle of the executables in opts
+func Create(opts Options) error {
+	goos := opts.GOOS
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	pythonLink := "shared"
+	if opts.Static {
+		pythonLink = "static"
+	}
+	if err := os.MkdirAll(opts.OutDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", opts.OutDir, err)
+	}
+	err := env.WriteBundleInfo(opts.OutDir, &env.BundleInfo{
+		PythonVersion: opts.Info.Version,
+		PythonLink:    pythonLink,
+	})
+	if err != nil {
+		return err
+	}
+	if goos == "windows" {
+		return createWindows(opts)
+	}
+
+	libDir := filepath.Join(opts.OutDir, "lib")
+	if err := os.MkdirAll(libDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", libDir, err)
+	}
+	if !opts.Static {
+		libs, err := copyLibPython(opts.Layout.LibDir, libDir, opts.Info, goos)
+		if err != nil {
+			return err
+		}
+		if goos == "darwin" {
+			if err := fixInstallNames(opts.Binaries, libs); err != nil {
+				return err
+			}
+		}
+	}
+
+	stdlib := stdlibDir(opts.Layout, opts.Info)
+	dst := filepath.Join(libDir, filepath.Base(stdlib))
+	if err := copyTree(stdlib, dst, skipStdlib); err != nil {
+		return fmt.Errorf("failed to copy the standard library: %v", err)
+	}
+	return nil
+}
+
+// createWindows lays out a bundle like a Windows Python installation
+func createWindows(opts Options) error {
+	home := opts.Layout.Home
+	for _, dir := range []string{"Lib", "DLLs"} {
+		src := filepath.Join(home, dir)
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+		if err := copyTree(src, filepath.Join(opts.OutDir, dir), skipStdlib); err != nil {
+			return fmt.Errorf("failed to copy %s: %v", dir, err)
+		}
+	}
+	// Windows loads DLLs from the directory of the executable
+	dlls, err := filepath.Glob(filepath.Join(home, "*.dll"))
+	if err != nil {
+		return err
+	}
+	for _, dll := range dlls {
+		if err := copyFile(dll, filepath.Join(BinDir(opts.OutDir), filepath.Base(dll))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stdlibDir returns the standard library directory of the Python installation,
+// e.g. lib/python3.13 or lib/python3.13t for free-threaded builds
+func stdlibDir(layout env.PythonLayout, info *env.PythonInfo) string {
+	dir := filepath.Join(layout.Home, "lib", "python"+info.MajorMinor+info.ABI())
+	if _, err := os.Stat(dir); err != nil && info.ABI() != "" {
+		return filepath.Join(layout.Home, "lib", "python"+info.MajorMinor)
+	}
+	return dir
+}
+
+// skipStdlib leaves out the static library and build files of the
+// config-3.x-* directory, which are only needed to build against Python
+func skipStdlib(rel string, fi os.FileInfo) bool {
+	return fi.IsDir() && strings.HasPrefix(filepath.Base(rel), "config-")
+}
+
+// copyLibPython copies libpython with its symlinks from srcDir to dstDir and
+// returns the names of the copied files
+func copyLibPython(srcDir, dstDir string, info *env.PythonInfo, goos string) ([]string, error) {
+	pattern := "lib" + info.LibName() + ".so*"
+	if goos == "darwin" {
+		pattern = "lib" + info.LibName() + "*.dylib"
+	}
+	matches, err := filepath.Glob(filepath.Join(srcDir, pattern))
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no %s found in %s", pattern, srcDir)
+	}
+	var names []string
+	for _, match := range matches {
+		name := filepath.Base(match)
+		if err := copyEntry(match, filepath.Join(dstDir, name)); err != nil {
+			return nil, err
+		}
+		names = append(names, filepath.Join(dstDir, name))
+	}
+	return names, nil
+}
+
+// fixInstallNames makes the executables load the bundled dylibs through the
+// rpath. got install records absolute install names in libpython.
+func fixInstallNames(binaries, dylibs []string) error {
+	for _, dylib := range dylibs {
+		if fi, err := os.Lstat(dylib); err != nil || fi.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+		name := "@rpath/" + filepath.Base(dylib)
+		if err := run("install_name_tool", "-id", name, dylib); err != nil {
+			return err
+		}
+		if err := codesign(dylib); err != nil {
+			return err
+		}
+	}
+	for _, bin := range binaries {
+		f, err := macho.Open(bin)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", bin, err)
+		}
+		imported, err := f.ImportedLibraries()
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", bin, err)
+		}
+		args := []string{}
+		for _, lib := range imported {
+			if strings.HasPrefix(filepath.Base(lib), "libpython") && !strings.HasPrefix(lib, "@rpath/") {
+				args = append(args, "-change", lib, "@rpath/"+filepath.Base(lib))
+			}
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if err := run("install_name_tool", append(args, bin)...); err != nil {
+			return err
+		}
+		if err := codesign(bin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// codesign re-signs a binary modified by install_name_tool, which arm64
+// macOS refuses to load otherwise
+func codesign(path string) error {
+	if runtime.GOOS != "darwin" || runtime.GOARCH != "arm64" {
+		return nil
+	}
+	return run("codesign", "--force", "--sign", "-", path)
+}
+
+func run(name string, args ...string) error {
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %v\n%s", name, strings.Join(args, " "), err, output)
+	}
+	return nil
+}
+
+// copyTree copies the directory src to dst, preserving symlinks, except for
+// the entries skip returns true for
+func copyTree(src, dst string, skip func(rel string, fi os.FileInfo) bool) error {
+	return filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if skip != nil && rel != "." && skip(rel, fi) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		target := filepath.Join(dst, rel)
+		if fi.IsDir() {
+			return os.MkdirAll(target, fi.Mode().Perm()|0700)
+		}
+		return copyEntry(path, target)
+	})
+}
+
+// copyEntry copies a file or symlink. Symlinks to files or directories
+// outside their directory are replaced by copies so the bundle stays
+// relocatable.
+func copyEntry(src, dst string) error {
+	fi, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSymlink != 0 {
+		link, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		target, err := os.Stat(src)
+		local := !filepath.IsAbs(link) && !strings.Contains(filepath.ToSlash(link), "/")
+		if err != nil || local {
+			os.Remove(dst)
+			return os.Symlink(link, dst)
+		}
+		if target.IsDir() {
+			return copyLinkedDir(src, dst)
+		}
+	}
+	return copyFile(src, dst)
+}
+
+// copyLinkedDir copies the directory the symlink src points to to dst
+func copyLinkedDir(src, dst string) error {
+	dir, err := filepath.EvalSymlinks(src)
+	if err != nil {
+		return err
+	}
+	parent, err := filepath.EvalSymlinks(filepath.Dir(src))
+	if err != nil {
+		return err
+	}
+	if rel, err := filepath.Rel(dir, parent); err == nil && !strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("symlink %s points to its own parent directory %s", src, dir)
+	}
+	os.RemoveAll(dst)
+	return copyTree(dir, dst, nil)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fi.Mode().Perm()|0200)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
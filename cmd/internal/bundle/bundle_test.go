@@ -0,0 +1,121 @@
+package bundle
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/gotray/got/internal/env"
+)
+
+func TestCreate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+	home := t.TempDir()
+	external := filepath.Join(t.TempDir(), "sitecustomize.py")
+	files := []string{
+		"lib/libpython3.13.so.1.0",
+		"lib/python3.13/os.py",
+		"lib/python3.13/site-packages/pkg/__init__.py",
+		"lib/python3.13/config-3.13-x86_64-linux-gnu/libpython3.13.a",
+	}
+	for _, file := range append(files, external) {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(home, file)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("libpython3.13.so.1.0", filepath.Join(home, "lib", "libpython3.13.so")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(external, filepath.Join(home, "lib", "python3.13", "sitecustomize.py")); err != nil {
+		t.Fatal(err)
+	}
+	editable := filepath.Join(t.TempDir(), "src", "mypkg")
+	if err := os.MkdirAll(editable, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(editable, "__init__.py"), []byte("mypkg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(editable, filepath.Join(home, "lib", "python3.13", "site-packages", "mypkg")); err != nil {
+		t.Fatal(err)
+	}
+
+	out := t.TempDir()
+	err := Create(Options{
+		OutDir: out,
+		Layout: env.PythonLayout{Home: home, LibDir: filepath.Join(home, "lib")},
+		Info:   &env.PythonInfo{MajorMinor: "3.13", TargetTriple: "x86_64-unknown-linux-gnu"},
+		GOOS:   "linux",
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	for _, file := range []string{env.BundleMarkerFile, "lib/libpython3.13.so.1.0", "lib/python3.13/os.py", "lib/python3.13/site-packages/pkg/__init__.py"} {
+		if _, err := os.Stat(filepath.Join(out, file)); err != nil {
+			t.Errorf("bundle is missing %s: %v", file, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(out, "lib", "python3.13", "config-3.13-x86_64-linux-gnu")); !os.IsNotExist(err) {
+		t.Errorf("bundle contains the config directory, Stat() error = %v", err)
+	}
+	if link, err := os.Readlink(filepath.Join(out, "lib", "libpython3.13.so")); err != nil || link != "libpython3.13.so.1.0" {
+		t.Errorf("libpython3.13.so links to %q, %v, want libpython3.13.so.1.0", link, err)
+	}
+	fi, err := os.Lstat(filepath.Join(out, "lib", "python3.13", "sitecustomize.py"))
+	if err != nil || fi.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("sitecustomize.py outside the installation should be copied, Lstat() = %v, %v", fi, err)
+	}
+	fi, err = os.Lstat(filepath.Join(out, "lib", "python3.13", "site-packages", "mypkg"))
+	if err != nil || !fi.IsDir() {
+		t.Errorf("directory mypkg outside the installation should be copied, Lstat() = %v, %v", fi, err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "lib", "python3.13", "site-packages", "mypkg", "__init__.py")); err != nil {
+		t.Errorf("bundle is missing mypkg/__init__.py: %v", err)
+	}
+}
+
+func TestCreateStatic(t *testing.T) {
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, "lib", "python3.13t"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	out := t.TempDir()
+	err := Create(Options{
+		OutDir: out,
+		Layout: env.PythonLayout{Home: home, LibDir: filepath.Join(home, "lib")},
+		Info:   &env.PythonInfo{MajorMinor: "3.13", ABITag: "t", TargetTriple: "x86_64-unknown-linux-gnu"},
+		Static: true,
+		GOOS:   "linux",
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "lib", "python3.13t")); err != nil {
+		t.Errorf("bundle is missing the free-threaded standard library: %v", err)
+	}
+}
+
+func TestRpath(t *testing.T) {
+	tests := map[string]string{
+		"linux":   "$ORIGIN/../lib",
+		"freebsd": "$ORIGIN/../lib",
+		"darwin":  "@executable_path/../lib",
+		"windows": "",
+	}
+	for goos, want := range tests {
+		if got := Rpath(goos); got != want {
+			t.Errorf("Rpath(%q) = %q, want %q", goos, got, want)
+		}
+	}
+}
@@ -0,0 +1,476 @@
+package doctor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gotray/got/cmd/internal/binutil"
+	"github.com/gotray/got/cmd/internal/pkgconfig"
+	"github.com/gotray/got/cmd/internal/rungo"
+	"github.com/gotray/got/internal/env"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// reinstallFix reinstalls the project dependencies without touching its sources
+const reinstallFix = "run `got init .` in the project root and answer no to overwriting files to reinstall dependencies"
+
+// Finding is the result of a single check
+type Finding struct {
+	Check   string `json:"check"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"`
+}
+
+// Report is the result of all checks
+type Report struct {
+	ProjectRoot string    `json:"project_root,omitempty"`
+	Findings    []Finding `json:"findings"`
+}
+
+func (r *Report) add(check string, status Status, message, fix string) {
+	r.Findings = append(r.Findings, Finding{Check: check, Status: status, Message: message, Fix: fix})
+}
+
+// Failed reports whether any check failed
+func (r *Report) Failed() bool {
+	for _, f := range r.Findings {
+		if f.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// checker holds the project state shared by the checks
+type checker struct {
+	report      *Report
+	projectRoot string
+	layout      env.PythonLayout
+	manifest    *env.Manifest
+	buildEnv    []string
+	envFile     map[string]string
+}
+
+// Run checks the Got project containing dir and the tools needed to build it
+func Run(dir string) *Report {
+	r := &Report{}
+	projectRoot, err := rungo.FindProjectRoot(dir)
+	if err != nil {
+		// A project whose Python is gone is still worth diagnosing
+		projectRoot = findDepsDir(dir)
+		if projectRoot == "" {
+			r.add("project", StatusFail, fmt.Sprintf("no Got project found in %s or its parents: %v", dir, err),
+				"run got commands inside a project created by `got init`, or run `got init .` to set one up here")
+			checkCCompiler(r, environ(nil))
+			return r
+		}
+		r.add("project", StatusWarn, "found "+env.GetDepsDir(projectRoot)+" but not its Python interpreter", reinstallFix)
+	} else {
+		r.add("project", StatusOK, "project root is "+projectRoot, "")
+	}
+	r.ProjectRoot = projectRoot
+
+	c := &checker{
+		report:      r,
+		projectRoot: projectRoot,
+		layout:      env.GetPythonLayout(projectRoot),
+		buildEnv:    environ(env.BuildEnv(projectRoot)),
+	}
+	c.manifest, _ = env.ReadManifest(projectRoot)
+
+	c.checkEnvFile()
+	c.checkGo()
+	c.checkPython()
+	checkCCompiler(r, c.buildEnv)
+	c.checkPkgConfig()
+	c.checkLibPython()
+	return r
+}
+
+// environ returns the current environment overridden by vars
+func environ(vars map[string]string) []string {
+	result := []string{}
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		if _, ok := vars[key]; !ok {
+			result = append(result, kv)
+		}
+	}
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		result = append(result, k+"="+vars[k])
+	}
+	return result
+}
+
+// getenv returns the value of key in environment e
+func getenv(e []string, key string) string {
+	for _, kv := range e {
+		if value, ok := strings.CutPrefix(kv, key+"="); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// lookPath searches for file in the PATH of environment e
+func lookPath(e []string, file string) (string, error) {
+	for _, dir := range filepath.SplitList(getenv(e, "PATH")) {
+		for _, ext := range executableExts() {
+			candidate := filepath.Join(dir, file+ext)
+			if fi, err := os.Stat(candidate); err == nil && !fi.IsDir() {
+				return candidate, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%s not found in PATH", file)
+}
+
+func executableExts() []string {
+	if runtime.GOOS == "windows" {
+		return []string{".exe", ".bat", ".cmd", ""}
+	}
+	return []string{""}
+}
+
+// run executes name with args in environment e and returns its combined output
+func run(e []string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Env = e
+	out, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+// firstLine returns the first line of s
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return strings.TrimSpace(line)
+}
+
+// checkEnvFile verifies that the paths recorded in env.txt still exist
+func (c *checker) checkEnvFile() {
+	envs, err := env.ReadEnvFile(c.projectRoot)
+	if err != nil {
+		c.report.add("env.txt", StatusFail, err.Error(), reinstallFix)
+		return
+	}
+	c.envFile = envs
+
+	home := envs["PYTHONHOME"]
+	if home == "" {
+		c.report.add("env.txt", StatusFail, "PYTHONHOME is not set in env.txt", reinstallFix)
+		return
+	}
+	if _, err := os.Stat(home); err != nil {
+		c.report.add("env.txt", StatusFail,
+			fmt.Sprintf("PYTHONHOME %s does not exist; Python fails with \"Fatal Python error: init_fs_encoding\"", home),
+			reinstallFix)
+		return
+	}
+	if !samePath(home, c.layout.Home) {
+		c.report.add("env.txt", StatusFail,
+			fmt.Sprintf("PYTHONHOME %s in env.txt differs from the installed Python %s", home, c.layout.Home),
+			reinstallFix)
+		return
+	}
+
+	var missing []string
+	for _, p := range filepath.SplitList(envs["PYTHONPATH"]) {
+		// sys.path always lists the stdlib zip, which usually doesn't exist
+		if p == "" || strings.HasSuffix(p, ".zip") {
+			continue
+		}
+		if _, err := os.Stat(p); err != nil {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) > 0 {
+		c.report.add("env.txt", StatusWarn,
+			"PYTHONPATH entries do not exist: "+strings.Join(missing, ", "), reinstallFix)
+		return
+	}
+	c.report.add("env.txt", StatusOK, "PYTHONHOME and PYTHONPATH exist", "")
+}
+
+// checkGo runs the project's Go and compares it with go.mod
+func (c *checker) checkGo() {
+	goBin := filepath.Join(env.GetGoBinDir(c.projectRoot), "go")
+	if runtime.GOOS == "windows" {
+		goBin += ".exe"
+	}
+	if _, err := os.Stat(goBin); err != nil {
+		c.report.add("go", StatusFail, "Go is not installed in "+env.GetGoRoot(c.projectRoot), reinstallFix)
+		return
+	}
+	// GOTOOLCHAIN=local keeps go from switching to the toolchain go.mod asks for
+	out, err := run(append(c.buildEnv, "GOTOOLCHAIN=local"), goBin, "env", "GOVERSION")
+	if err != nil {
+		c.report.add("go", StatusFail, fmt.Sprintf("%s does not run: %v: %s", goBin, err, out), reinstallFix)
+		return
+	}
+	installed := strings.TrimPrefix(firstLine(out), "go")
+	c.report.add("go", StatusOK, "Go "+installed+" at "+goBin, "")
+
+	if c.manifest != nil && c.manifest.Go.Version != "" && c.manifest.Go.Version != installed {
+		c.report.add("go version", StatusWarn,
+			fmt.Sprintf("Go %s is installed but the manifest records %s", installed, c.manifest.Go.Version),
+			reinstallFix)
+	}
+
+	required, toolchain, err := goModVersions(filepath.Join(c.projectRoot, "go.mod"))
+	if err != nil {
+		c.report.add("go.mod", StatusWarn, err.Error(), "")
+		return
+	}
+	switch {
+	case required != "" && compareVersions(installed, required) < 0:
+		c.report.add("go.mod", StatusFail,
+			fmt.Sprintf("go.mod requires Go %s but Go %s is installed", required, installed),
+			fmt.Sprintf("run `got init . --go-version %s` to install a matching Go, or lower the go directive in go.mod", required))
+	case toolchain != "" && compareVersions(installed, toolchain) < 0:
+		c.report.add("go.mod", StatusWarn,
+			fmt.Sprintf("go.mod selects toolchain go%s but Go %s is installed; go will download it", toolchain, installed),
+			fmt.Sprintf("run `got init . --go-version %s` to install it into the project", toolchain))
+	default:
+		c.report.add("go.mod", StatusOK, "installed Go satisfies go.mod", "")
+	}
+}
+
+// goModVersions returns the go and toolchain versions declared in a go.mod file
+func goModVersions(path string) (string, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read go.mod: %v", err)
+	}
+	defer f.Close()
+
+	var goVersion, toolchain string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "go":
+			goVersion = fields[1]
+		case "toolchain":
+			toolchain = strings.TrimPrefix(fields[1], "go")
+		}
+	}
+	return goVersion, toolchain, scanner.Err()
+}
+
+// compareVersions compares dotted versions such as 1.21 and 1.23.3,
+// ignoring pre-release suffixes
+func compareVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = leadingInt(pa[i])
+		}
+		if i < len(pb) {
+			y = leadingInt(pb[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func leadingInt(s string) int {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(s[:end])
+	return n
+}
+
+// checkPython runs the project's Python with the env.txt environment
+func (c *checker) checkPython() {
+	python, err := c.layout.PythonEnv().Python()
+	if err != nil {
+		c.report.add("python", StatusFail, err.Error(), reinstallFix)
+		return
+	}
+	pyEnv := c.buildEnv
+	if c.envFile != nil {
+		pyEnv = environ(map[string]string{
+			"PYTHONHOME": c.envFile["PYTHONHOME"],
+			"PYTHONPATH": c.envFile["PYTHONPATH"],
+		})
+	}
+	out, err := run(pyEnv, python, "-c", "import platform; print(platform.python_version())")
+	if err != nil {
+		fix := reinstallFix
+		if strings.Contains(out, "init_fs_encoding") {
+			fix = "env.txt points Python at a missing standard library; " + reinstallFix
+		}
+		c.report.add("python", StatusFail, fmt.Sprintf("%s does not run: %v: %s", python, err, firstLine(out)), fix)
+		return
+	}
+	installed := firstLine(out)
+	c.report.add("python", StatusOK, "Python "+installed+" at "+python, "")
+
+	if c.manifest != nil && c.manifest.Python.Version != "" && c.manifest.Python.Version != installed {
+		c.report.add("python version", StatusWarn,
+			fmt.Sprintf("Python %s runs but the manifest records %s", installed, c.manifest.Python.Version),
+			reinstallFix)
+	}
+}
+
+// checkCCompiler looks for the C compiler cgo uses
+func checkCCompiler(r *Report, e []string) {
+	candidates := []string{"gcc", "cc", "clang"}
+	if fields := strings.Fields(getenv(e, "CC")); len(fields) > 0 {
+		candidates = fields[:1]
+	}
+	for _, name := range candidates {
+		path := name
+		var err error
+		if !filepath.IsAbs(name) {
+			path, err = lookPath(e, name)
+		}
+		if err != nil {
+			continue
+		}
+		out, err := run(e, path, "--version")
+		if err != nil {
+			r.add("c compiler", StatusFail, fmt.Sprintf("%s does not run: %v", path, err),
+				"reinstall the C compiler or point CC at a working one")
+			return
+		}
+		r.add("c compiler", StatusOK, fmt.Sprintf("%s (%s)", path, firstLine(out)), "")
+		return
+	}
+	r.add("c compiler", StatusFail, "no C compiler found, cgo cannot build Python bindings", cCompilerFix())
+}
+
+func cCompilerFix() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "install the Xcode command line tools with `xcode-select --install`"
+	case "windows":
+		return reinstallFix + " (installs MinGW into .deps/mingw)"
+	default:
+		return "install gcc, e.g. `apt install build-essential`, `dnf install gcc` or `apk add build-base`, set CC, or run `got init . --c-toolchain zig` to install a C toolchain into the project"
+	}
+}
+
+// checkPkgConfig resolves the cgo flags for python3-embed the way got's
+// pkg-config does for cgo
+func (c *checker) checkPkgConfig() {
+	r := pkgconfig.NewResolverFromEnviron(c.buildEnv)
+	pkgs, err := r.Resolve([]pkgconfig.Requirement{{Name: "python3-embed"}}, false)
+	if err == nil {
+		var flags []string
+		if flags, err = r.Cflags(pkgs); err == nil {
+			if pkgs[0].Path != filepath.Join(c.layout.PkgConfigDir, "python3-embed.pc") {
+				c.report.add("pkg-config", StatusFail,
+					fmt.Sprintf("python3-embed resolved to %s instead of the project's Python in %s", pkgs[0].Path, c.layout.PkgConfigDir),
+					reinstallFix)
+				return
+			}
+			c.report.add("pkg-config", StatusOK, "python3-embed cflags: "+pkgconfig.Quote(flags), "")
+			return
+		}
+	}
+	c.report.add("pkg-config", StatusFail,
+		fmt.Sprintf("`got pkg-config --cflags python3-embed` failed with PKG_CONFIG_PATH=%s: %s", c.layout.PkgConfigDir, firstLine(err.Error())),
+		reinstallFix)
+}
+
+// checkLibPython verifies the shared libpython exists and matches this machine
+func (c *checker) checkLibPython() {
+	if c.manifest != nil && c.manifest.Python.LinkMode == "static" {
+		c.report.add("libpython", StatusOK, "libpython is linked statically", "")
+		return
+	}
+	lib, err := findLibPython(c.layout)
+	if err != nil {
+		c.report.add("libpython", StatusFail, err.Error(), reinstallFix)
+		return
+	}
+	_, arches, err := binutil.Format(lib)
+	if err != nil {
+		c.report.add("libpython", StatusFail, fmt.Sprintf("cannot load %s: %v", lib, err), reinstallFix)
+		return
+	}
+	for _, arch := range arches {
+		if arch == runtime.GOARCH {
+			c.report.add("libpython", StatusOK, lib, "")
+			return
+		}
+	}
+	c.report.add("libpython", StatusFail,
+		fmt.Sprintf("%s is built for %s, not %s", lib, strings.Join(arches, ", "), runtime.GOARCH),
+		"install a Python for "+runtime.GOARCH+": "+reinstallFix)
+}
+
+// findLibPython returns the shared libpython of layout
+func findLibPython(layout env.PythonLayout) (string, error) {
+	dir, patterns := layout.LibDir, []string{"libpython*.so*"}
+	switch runtime.GOOS {
+	case "darwin":
+		patterns = []string{"libpython*.dylib"}
+	case "windows":
+		dir, patterns = layout.Home, []string{"python3*.dll"}
+	}
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		if len(matches) > 0 {
+			return matches[0], nil
+		}
+	}
+	return "", fmt.Errorf("no shared libpython found in %s", dir)
+}
+
+// findDepsDir returns the closest directory from dir upwards containing .deps
+func findDepsDir(dir string) string {
+	for {
+		if fi, err := os.Stat(env.GetDepsDir(dir)); err == nil && fi.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// samePath reports whether two paths refer to the same location
+func samePath(a, b string) bool {
+	ea, errA := filepath.EvalSymlinks(a)
+	eb, errB := filepath.EvalSymlinks(b)
+	if errA == nil && errB == nil {
+		return ea == eb
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
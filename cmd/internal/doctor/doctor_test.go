@@ -0,0 +1,127 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gotray/got/internal/env"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.23.3", "1.21", 1},
+		{"1.21", "1.21.0", 0},
+		{"1.22rc1", "1.22", 0},
+		{"1.9", "1.21", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestGoModVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "go.mod")
+	content := "module example.com/app\n\ngo 1.22.1\n\ntoolchain go1.23.3\n\nrequire github.com/gotray/go-python v0.1.0\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	goVersion, toolchain, err := goModVersions(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if goVersion != "1.22.1" || toolchain != "1.23.3" {
+		t.Errorf("goModVersions() = %q, %q, want 1.22.1, 1.23.3", goVersion, toolchain)
+	}
+}
+
+func TestCheckCCompiler(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the C compiler")
+	}
+	binDir := t.TempDir()
+	script := "#!/bin/sh\necho 'fakecc 1.0'\n"
+	if err := os.WriteFile(filepath.Join(binDir, "fakecc"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		cc      string
+		status  Status
+		message string
+	}{
+		{"fakecc -m64", StatusOK, "fakecc 1.0"},
+		{" ", StatusFail, "no C compiler found"},
+		{"", StatusFail, "no C compiler found"},
+		{"missingcc", StatusFail, "no C compiler found"},
+	}
+	for _, tt := range tests {
+		r := &Report{}
+		checkCCompiler(r, []string{"PATH=" + binDir, "CC=" + tt.cc})
+		if len(r.Findings) != 1 {
+			t.Fatalf("CC=%q: checkCCompiler() reported %d findings, want 1", tt.cc, len(r.Findings))
+		}
+		f := r.Findings[0]
+		if f.Status != tt.status || !strings.Contains(f.Message, tt.message) {
+			t.Errorf("CC=%q: finding = %s %q, want %s containing %q", tt.cc, f.Status, f.Message, tt.status, tt.message)
+		}
+	}
+}
+
+func TestRunBrokenProject(t *testing.T) {
+	projectDir := t.TempDir()
+	if err := os.MkdirAll(env.GetDepsDir(projectDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	missingHome := filepath.Join(projectDir, "moved", "python")
+	if err := env.WriteEnvFile(projectDir, missingHome, filepath.Join(missingHome, "lib")); err != nil {
+		t.Fatal(err)
+	}
+	subDir := filepath.Join(projectDir, "cmd", "app")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	report := Run(subDir)
+	if report.ProjectRoot != projectDir {
+		t.Errorf("Run() project root = %s, want %s", report.ProjectRoot, projectDir)
+	}
+	if !report.Failed() {
+		t.Error("Run() reports no failures for a project without Go and Python")
+	}
+
+	findings := map[string]Finding{}
+	for _, f := range report.Findings {
+		if _, ok := findings[f.Check]; !ok {
+			findings[f.Check] = f
+		}
+	}
+	want := map[string]struct {
+		status  Status
+		message string
+	}{
+		"project": {StatusWarn, "not its Python interpreter"},
+		"env.txt": {StatusFail, "init_fs_encoding"},
+		"go":      {StatusFail, "Go is not installed"},
+		"python":  {StatusFail, ""},
+	}
+	for check, w := range want {
+		f, ok := findings[check]
+		if !ok {
+			t.Errorf("Run() has no %s finding", check)
+			continue
+		}
+		if f.Status != w.status || !strings.Contains(f.Message, w.message) {
+			t.Errorf("%s finding = %s %q, want %s containing %q", check, f.Status, f.Message, w.status, w.message)
+		}
+		if f.Fix == "" {
+			t.Errorf("%s finding has no fix", check)
+		}
+	}
+}
@@ -13,9 +13,14 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/gotray/got/internal/env"
 	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
 )
 
+// pythonInfoArchivePath is the location of PYTHON.json in python-build-standalone archives
+const pythonInfoArchivePath = "python/PYTHON.json"
+
 // getCacheDir returns the cache directory for downloaded files
 func getCacheDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
@@ -32,7 +37,7 @@ func getCacheDir() (string, error) {
 // getFullExtension returns the full extension for a filename (e.g., ".tar.gz" for "file.tar.gz")
 func getFullExtension(filename string) string {
 	// Handle common multi-level extensions
-	for _, ext := range []string{".tar.gz", ".tar.zst"} {
+	for _, ext := range []string{".tar.gz", ".tar.zst", ".tar.xz"} {
 		if strings.HasSuffix(filename, ext) {
 			return ext
 		}
@@ -107,6 +112,14 @@ func downloadFileWithCache(url string) (string, error) {
 }
 
 func downloadAndExtract(name, version, url, dir, trimPrefix string, verbose bool) error {
+	return downloadAndExtractFiltered(name, version, url, dir, trimPrefix, nil, verbose)
+}
+
+// downloadAndExtractFiltered is downloadAndExtract leaving out the entries
+// skip returns true for. skip gets the slash-separated paths of the entries
+// relative to dir, and of the targets of hard links, and is only supported
+// for tar.zst archives.
+func downloadAndExtractFiltered(name, version, url, dir, trimPrefix string, skip func(name, link string) bool, verbose bool) error {
 	if verbose {
 		fmt.Printf("Downloading %s %s from %s\n", name, version, url)
 	}
@@ -130,7 +143,9 @@ func downloadAndExtract(name, version, url, dir, trimPrefix string, verbose bool
 	} else if strings.HasSuffix(path, ".tar.gz") {
 		return extractTarGz(path, dir)
 	} else if strings.HasSuffix(path, ".tar.zst") {
-		return extractTarZst(path, dir, trimPrefix, verbose)
+		return extractTarZst(path, dir, trimPrefix, skip, verbose)
+	} else if strings.HasSuffix(path, ".tar.xz") {
+		return extractTarXz(path, dir, trimPrefix)
 	} else {
 		return fmt.Errorf("unsupported file extension for %s %s", name, version)
 	}
@@ -239,8 +254,9 @@ func extractTarGz(tarFile, destDir string) error {
 	return nil
 }
 
-// extractTarZst extracts a tar.zst file to a destination directory
-func extractTarZst(src, dst, trimPrefix string, verbose bool) error {
+// extractTarZst extracts a tar.zst file to a destination directory, leaving
+// out the entries skip returns true for if it is not nil
+func extractTarZst(src, dst, trimPrefix string, skip func(name, link string) bool, verbose bool) error {
 	if verbose {
 		fmt.Printf("Extracting from %s to %s\n", src, dst)
 	}
@@ -274,15 +290,26 @@ func extractTarZst(src, dst, trimPrefix string, verbose bool) error {
 		name := header.Name
 
 		if trimPrefix != "" {
-			if !strings.HasPrefix(header.Name, trimPrefix) {
+			if header.Name == pythonInfoArchivePath {
+				// Keep the build description next to the extracted tree
+				name = env.PythonInfoFile
+			} else if !strings.HasPrefix(header.Name, trimPrefix) {
 				continue
+			} else {
+				// Remove the trimPrefix prefix
+				name = strings.TrimPrefix(header.Name, trimPrefix)
+				if name == "" {
+					continue
+				}
 			}
+		}
 
-			// Remove the trimPrefix prefix
-			name = strings.TrimPrefix(header.Name, trimPrefix)
-			if name == "" {
-				continue
-			}
+		link := ""
+		if header.Typeflag == tar.TypeLink {
+			link = strings.TrimPrefix(strings.TrimPrefix(header.Linkname, trimPrefix), "/")
+		}
+		if skip != nil && skip(strings.TrimPrefix(name, "/"), link) {
+			continue
 		}
 
 		path := filepath.Join(dst, name)
@@ -338,7 +365,7 @@ func extractTarZst(src, dst, trimPrefix string, verbose bool) error {
 			}
 
 			// Create hard link relative to the destination directory
-			targetPath := filepath.Join(dst, strings.TrimPrefix(header.Linkname, "python/install/"))
+			targetPath := filepath.Join(dst, link)
 			if err := os.Link(targetPath, path); err != nil {
 				return fmt.Errorf("error creating hard link %s -> %s: %v", path, targetPath, err)
 			}
@@ -347,3 +374,67 @@ func extractTarZst(src, dst, trimPrefix string, verbose bool) error {
 
 	return nil
 }
+
+// extractTarXz extracts a tar.xz file to the specified directory, dropping
+// trimPrefix from the paths of its entries
+func extractTarXz(tarFile, destDir, trimPrefix string) error {
+	file, err := os.Open(tarFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	xzr, err := xz.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("error creating xz decoder: %v", err)
+	}
+
+	tr := tar.NewReader(xzr)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimPrefix(strings.TrimPrefix(header.Name, trimPrefix), "/")
+		if name == "" {
+			continue
+		}
+		destPath := filepath.Join(destDir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(destPath, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+				return err
+			}
+			outFile, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(header.Mode))
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(outFile, tr); err != nil {
+				outFile.Close()
+				return err
+			}
+			outFile.Close()
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+				return err
+			}
+			if err := os.RemoveAll(destPath); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, destPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package install
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// pythonPlatforms lists the GOOS/GOARCH pairs python-build-standalone publishes builds for
+var pythonPlatforms = map[string][]string{
+	"darwin":  {"amd64", "arm64"},
+	"linux":   {"amd64", "arm64", "386"},
+	"windows": {"amd64", "386"},
+}
+
+// muslArches lists the architectures with musl builds
+var muslArches = []string{"amd64", "arm64"}
+
+const (
+	// freeThreadedMinVersion is the first CPython release with a free-threaded build
+	freeThreadedMinVersion = "3.13"
+)
+
+// PythonSpecError reports a Python build that python-build-standalone does not publish
+type PythonSpecError struct {
+	Reason     string
+	Suggestion string
+}
+
+func (e *PythonSpecError) Error() string {
+	if e.Suggestion == "" {
+		return e.Reason
+	}
+	return e.Reason + "; " + e.Suggestion
+}
+
+// validatePythonSpec checks up front that the requested Python build exists.
+// Static rules catch combinations that never existed; when a release index is
+// available the exact archive name is also checked against the published
+// assets. Failures suggest the nearest published build.
+func validatePythonSpec(spec pythonSpec, releases []PythonRelease) error {
+	arches, ok := pythonPlatforms[spec.OS]
+	if !ok {
+		return &PythonSpecError{
+			Reason:     fmt.Sprintf("python-build-standalone has no builds for %s", spec.OS),
+			Suggestion: "supported operating systems are " + strings.Join(sortedKeys(pythonPlatforms), ", "),
+		}
+	}
+	if !slices.Contains(arches, spec.Arch) {
+		return &PythonSpecError{
+			Reason:     fmt.Sprintf("python-build-standalone has no builds for %s/%s", spec.OS, spec.Arch),
+			Suggestion: fmt.Sprintf("supported architectures for %s are %s", spec.OS, strings.Join(arches, ", ")),
+		}
+	}
+	if spec.Libc == libcMusl {
+		if spec.OS != "linux" {
+			return &PythonSpecError{Reason: fmt.Sprintf("musl builds are only published for Linux, not %s", spec.OS)}
+		}
+		if !slices.Contains(muslArches, spec.Arch) {
+			return &PythonSpecError{
+				Reason:     fmt.Sprintf("python-build-standalone has no musl builds for %s", spec.Arch),
+				Suggestion: "musl builds are available for " + strings.Join(muslArches, ", ") + ", or use --libc gnu",
+			}
+		}
+	}
+	if spec.Microarch != "" && (spec.OS != "linux" || spec.Arch != "amd64") {
+		return &PythonSpecError{
+			Reason:     fmt.Sprintf("x86-64-%s builds are only published for linux/amd64", spec.Microarch),
+			Suggestion: "drop --python-microarch",
+		}
+	}
+	if _, ok := parseVersion(spec.Version); !ok {
+		return &PythonSpecError{Reason: fmt.Sprintf("invalid Python version %q", spec.Version)}
+	}
+	if spec.Debug && spec.OS == "windows" {
+		return &PythonSpecError{
+			Reason:     "debug builds of Python are not published for Windows",
+			Suggestion: "drop --debug",
+		}
+	}
+	if spec.FreeThreaded && compareVersions(spec.Version, freeThreadedMinVersion) < 0 {
+		err := &PythonSpecError{
+			Reason:     fmt.Sprintf("free-threaded builds require Python ≥%s, got %s", freeThreadedMinVersion, spec.Version),
+			Suggestion: "drop --python-free-threaded or use --python-version " + freeThreadedMinVersion,
+		}
+		if nearest, ok := nearestPythonBuild(spec, releases); ok {
+			err.Suggestion = "nearest build is " + nearest.describe()
+		}
+		return err
+	}
+
+	filename := getPythonFilename(spec)
+	if filename == "" {
+		return &PythonSpecError{Reason: fmt.Sprintf("unsupported platform %s/%s", spec.OS, spec.Arch)}
+	}
+
+	// Check the archive against the index, unless the index doesn't know about the build date
+	for _, rel := range releases {
+		if rel.BuildDate != spec.BuildDate {
+			continue
+		}
+		if slices.Contains(rel.Assets, filename) {
+			return nil
+		}
+		err := &PythonSpecError{
+			Reason: fmt.Sprintf("%s is not published in python-build-standalone release %s", filename, spec.BuildDate),
+		}
+		if nearest, ok := nearestPythonBuild(spec, releases); ok {
+			err.Suggestion = "nearest build is " + nearest.describe()
+		}
+		return err
+	}
+	return nil
+}
+
+// pythonBuildCandidate is a published version/build date pair
+type pythonBuildCandidate struct {
+	version   string
+	buildDate string
+}
+
+func (c pythonBuildCandidate) describe() string {
+	return fmt.Sprintf("%s+%s (--python-version %s --python-build-date %s)", c.version, c.buildDate, c.version, c.buildDate)
+}
+
+// nearestPythonBuild finds the published build closest to spec that has the
+// same platform and variant. It prefers the requested version from another
+// build date, then the newest patch release of the same minor version, then
+// the lowest newer version, and finally the newest older version.
+func nearestPythonBuild(spec pythonSpec, releases []PythonRelease) (pythonBuildCandidate, bool) {
+	wantMinor := majorMinor(spec.Version)
+	if spec.FreeThreaded && compareVersions(spec.Version, freeThreadedMinVersion) < 0 {
+		wantMinor = freeThreadedMinVersion
+	}
+
+	var candidates []pythonBuildCandidate
+	for _, rel := range releases {
+		for _, v := range rel.Versions() {
+			if !isExactVersion(v) {
+				continue
+			}
+			s := spec
+			s.Version, s.BuildDate = v, rel.BuildDate
+			if slices.Contains(rel.Assets, getPythonFilename(s)) {
+				candidates = append(candidates, pythonBuildCandidate{v, rel.BuildDate})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return pythonBuildCandidate{}, false
+	}
+
+	// Newest first so the first match in each pass wins
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if c := compareVersions(candidates[i].version, candidates[j].version); c != 0 {
+			return c > 0
+		}
+		return candidates[i].buildDate > candidates[j].buildDate
+	})
+
+	for _, c := range candidates {
+		if c.version == spec.Version {
+			return c, true
+		}
+	}
+	for _, c := range candidates {
+		if majorMinor(c.version) == wantMinor {
+			return c, true
+		}
+	}
+	for i := len(candidates) - 1; i >= 0; i-- {
+		if compareVersions(candidates[i].version, spec.Version) > 0 {
+			return candidates[i], true
+		}
+	}
+	return candidates[0], true
+}
+
+// majorMinor returns the "X.Y" part of a version
+func majorMinor(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
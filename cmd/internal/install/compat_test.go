@@ -0,0 +1,142 @@
+package install
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePythonSpec(t *testing.T) {
+	releases := []PythonRelease{
+		{
+			BuildDate: "20241016",
+			Assets: []string{
+				"cpython-3.13.0+20241016-x86_64-unknown-linux-gnu-pgo-full.tar.zst",
+				"cpython-3.13.0+20241016-x86_64-unknown-linux-gnu-freethreaded+pgo-full.tar.zst",
+				"cpython-3.12.7+20241016-x86_64-unknown-linux-gnu-pgo-full.tar.zst",
+			},
+		},
+		{
+			BuildDate: "20240909",
+			Assets: []string{
+				"cpython-3.12.6+20240909-x86_64-unknown-linux-gnu-pgo-full.tar.zst",
+				"cpython-3.12.6+20240909-x86_64-unknown-linux-gnu-debug-full.tar.zst",
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		spec     pythonSpec
+		releases []PythonRelease
+		wantErr  []string
+	}{
+		{
+			name: "published build",
+			spec: pythonSpec{Version: "3.13.0", BuildDate: "20241016", Arch: "amd64", OS: "linux"},
+		},
+		{
+			name: "published free-threaded build",
+			spec: pythonSpec{Version: "3.13.0", BuildDate: "20241016", Arch: "amd64", OS: "linux", FreeThreaded: true},
+		},
+		{
+			name: "unknown build date is not checked against the index",
+			spec: pythonSpec{Version: "3.13.0", BuildDate: "20250101", Arch: "amd64", OS: "linux"},
+		},
+		{
+			name:    "free-threaded before 3.13",
+			spec:    pythonSpec{Version: "3.12.7", BuildDate: "20241016", Arch: "amd64", OS: "linux", FreeThreaded: true},
+			wantErr: []string{"free-threaded builds require Python ≥3.13", "nearest build is 3.13.0+20241016"},
+		},
+		{
+			name:     "free-threaded before 3.13 without index",
+			spec:     pythonSpec{Version: "3.12.7", BuildDate: "20241016", Arch: "amd64", OS: "linux", FreeThreaded: true},
+			releases: []PythonRelease{},
+			wantErr:  []string{"free-threaded builds require Python ≥3.13", "drop --python-free-threaded"},
+		},
+		{
+			name:    "debug on windows",
+			spec:    pythonSpec{Version: "3.13.0", BuildDate: "20241016", Arch: "amd64", OS: "windows", Debug: true},
+			wantErr: []string{"not published for Windows"},
+		},
+		{
+			name:    "unsupported arch",
+			spec:    pythonSpec{Version: "3.13.0", BuildDate: "20241016", Arch: "386", OS: "darwin"},
+			wantErr: []string{"no builds for darwin/386", "amd64, arm64"},
+		},
+		{
+			name:    "musl on darwin",
+			spec:    pythonSpec{Version: "3.13.0", BuildDate: "20241016", Arch: "arm64", OS: "darwin", Libc: "musl"},
+			wantErr: []string{"only published for Linux"},
+		},
+		{
+			name:    "musl on 386",
+			spec:    pythonSpec{Version: "3.13.0", BuildDate: "20241016", Arch: "386", OS: "linux", Libc: "musl"},
+			wantErr: []string{"no musl builds for 386", "--libc gnu"},
+		},
+		{
+			name:    "unsupported os",
+			spec:    pythonSpec{Version: "3.13.0", BuildDate: "20241016", Arch: "amd64", OS: "freebsd"},
+			wantErr: []string{"no builds for freebsd"},
+		},
+		{
+			name:    "missing debug build suggests another build date",
+			spec:    pythonSpec{Version: "3.12.6", BuildDate: "20241016", Arch: "amd64", OS: "linux", Debug: true},
+			wantErr: []string{"is not published", "nearest build is 3.12.6+20240909"},
+		},
+		{
+			name:    "version not in build date suggests same minor",
+			spec:    pythonSpec{Version: "3.12.6", BuildDate: "20241016", Arch: "amd64", OS: "linux"},
+			wantErr: []string{"nearest build is 3.12.6+20240909"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rels := releases
+			if tt.releases != nil {
+				rels = tt.releases
+			}
+			err := validatePythonSpec(tt.spec, rels)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("validatePythonSpec() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("validatePythonSpec() error = nil, want %v", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("validatePythonSpec() error = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveLibc(t *testing.T) {
+	tests := []struct {
+		libc, goos string
+		want       string
+		wantErr    bool
+	}{
+		{libc: "auto", goos: "darwin", want: ""},
+		{libc: "", goos: "windows", want: ""},
+		{libc: "gnu", goos: "linux", want: "gnu"},
+		{libc: "glibc", goos: "linux", want: "gnu"},
+		{libc: "musl", goos: "linux", want: "musl"},
+		{libc: "musl", goos: "darwin", wantErr: true},
+		{libc: "uclibc", goos: "linux", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := resolveLibc(tt.libc, tt.goos)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolveLibc(%q, %q) error = %v, wantErr %v", tt.libc, tt.goos, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resolveLibc(%q, %q) = %q, want %q", tt.libc, tt.goos, got, tt.want)
+		}
+	}
+}
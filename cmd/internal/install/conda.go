@@ -0,0 +1,192 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/gotray/got/internal/env"
+)
+
+// condaPythonPattern matches the conda-meta record of the python package, capturing its version
+var condaPythonPattern = regexp.MustCompile(`^python-(\d+\.\d+\.\d+)-.*\.json$`)
+
+// condaPython is the Python installed in a conda environment
+type condaPython struct {
+	Prefix       string
+	Executable   string
+	LibDir       string // directory containing libpython
+	LibPython    string
+	IncludeDir   string
+	PkgConfigDir string // the environment's own pkg-config directory
+	Version      string
+	MajorMinor   string
+	ABI          string
+}
+
+// libName returns the name of libpython as passed to -l
+func (c *condaPython) libName() string {
+	if runtime.GOOS == "windows" {
+		return "python" + strings.ReplaceAll(c.MajorMinor, ".", "") + c.ABI
+	}
+	return "python" + c.MajorMinor + c.ABI
+}
+
+// findCondaPython locates the interpreter, libpython and headers of a conda
+// environment from its directory layout, without running anything in it
+func findCondaPython(prefix string) (*condaPython, error) {
+	prefix, err := filepath.Abs(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %v", err)
+	}
+	metaDir := filepath.Join(prefix, "conda-meta")
+	entries, err := os.ReadDir(metaDir)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a conda environment: %v", prefix, err)
+	}
+
+	c := &condaPython{Prefix: prefix}
+	for _, entry := range entries {
+		if m := condaPythonPattern.FindStringSubmatch(entry.Name()); m != nil {
+			c.Version = m[1]
+		}
+		if strings.HasPrefix(entry.Name(), "python-freethreading-") {
+			c.ABI = "t"
+		}
+	}
+	if c.Version == "" {
+		return nil, fmt.Errorf("python is not installed in conda environment %s", prefix)
+	}
+	c.MajorMinor = majorMinor(c.Version)
+
+	var exeCandidates, libCandidates []string
+	if runtime.GOOS == "windows" {
+		c.LibDir = prefix
+		c.IncludeDir = filepath.Join(prefix, "include")
+		exeCandidates = []string{filepath.Join(prefix, "python.exe")}
+		libCandidates = []string{filepath.Join(prefix, c.libName()+".dll")}
+	} else {
+		c.LibDir = filepath.Join(prefix, "lib")
+		c.IncludeDir = filepath.Join(prefix, "include", "python"+c.MajorMinor+c.ABI)
+		c.PkgConfigDir = filepath.Join(c.LibDir, "pkgconfig")
+		binDir := filepath.Join(prefix, "bin")
+		exeCandidates = []string{
+			filepath.Join(binDir, "python"+c.MajorMinor+c.ABI),
+			filepath.Join(binDir, "python3"),
+			filepath.Join(binDir, "python"),
+		}
+		libBase := filepath.Join(c.LibDir, "lib"+c.libName())
+		libCandidates = []string{libBase + ".so", libBase + ".dylib", libBase + ".a"}
+	}
+
+	for _, exe := range exeCandidates {
+		if fileExists(exe) {
+			c.Executable = exe
+			break
+		}
+	}
+	if c.Executable == "" {
+		return nil, fmt.Errorf("python executable not found in conda environment %s", prefix)
+	}
+	for _, lib := range libCandidates {
+		if fileExists(lib) {
+			c.LibPython = lib
+			break
+		}
+	}
+	if c.LibPython == "" {
+		return nil, fmt.Errorf("libpython not found in conda environment %s, looked for %s", prefix, strings.Join(libCandidates, ", "))
+	}
+	return c, nil
+}
+
+// condaPkgConfigDir returns the environment's pkg-config directory when it
+// provides python3-embed.pc, otherwise the files are generated into the project
+func condaPkgConfigDir(projectPath string, c *condaPython) (string, error) {
+	if c.PkgConfigDir != "" {
+		embed := filepath.Join(c.PkgConfigDir, fmt.Sprintf("python-%s%s-embed.pc", c.MajorMinor, c.ABI))
+		if fileExists(embed) && fileExists(filepath.Join(c.PkgConfigDir, "python3-embed.pc")) {
+			return c.PkgConfigDir, nil
+		}
+	}
+
+	spec := pcSpec{
+		Prefix:     filepath.ToSlash(c.Prefix),
+		LibDir:     filepath.ToSlash(c.LibDir),
+		IncludeDir: filepath.ToSlash(c.IncludeDir),
+		Version:    c.MajorMinor,
+		ABI:        c.ABI,
+		LibName:    c.libName(),
+		Static:     strings.HasSuffix(c.LibPython, ".a"),
+	}
+	if runtime.GOOS == "windows" {
+		spec.ExtLibName = "python3" + c.ABI
+	}
+	pkgConfigDir := env.GetPythonPkgConfigDir(projectPath)
+	if err := writePythonPkgConfig(pkgConfigDir, spec); err != nil {
+		return "", err
+	}
+	return pkgConfigDir, nil
+}
+
+// useCondaPython configures the project to use the Python of a conda environment
+func useCondaPython(projectPath, prefix string, manifest *env.Manifest, verbose bool) error {
+	c, err := findCondaPython(prefix)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Using Python %s from conda environment %s\n", c.Version, c.Prefix)
+	if verbose {
+		fmt.Printf("Interpreter %s, libpython %s\n", c.Executable, c.LibPython)
+	}
+
+	pkgConfigDir, err := condaPkgConfigDir(projectPath, c)
+	if err != nil {
+		return err
+	}
+
+	pyEnv := &env.PythonEnv{Root: c.Prefix, Exe: c.Executable}
+	pythonPath, err := pyEnv.GetPythonPath()
+	if err != nil {
+		return fmt.Errorf("failed to get Python path: %v", err)
+	}
+	if err := env.WriteEnvFile(projectPath, c.Prefix, pythonPath); err != nil {
+		return fmt.Errorf("error writing environment file: %v", err)
+	}
+
+	manifest.Python = env.PythonManifest{
+		Source:       env.PythonSourceConda,
+		Version:      c.Version,
+		ABI:          c.ABI,
+		FreeThreaded: c.ABI == "t",
+		LibName:      c.libName(),
+		Home:         c.Prefix,
+		Executable:   c.Executable,
+		LibDir:       c.LibDir,
+		PkgConfigDir: pkgConfigDir,
+	}
+	if strings.HasSuffix(c.LibPython, ".a") {
+		manifest.Python.LinkMode = "static"
+	} else {
+		manifest.Python.LinkMode = "shared"
+	}
+	return nil
+}
+
+// usePythonFrom configures the project to use the Python described by from,
+// in the form "<kind>:<path>"
+func usePythonFrom(projectPath, from string, manifest *env.Manifest, verbose bool) error {
+	kind, path, ok := strings.Cut(from, ":")
+	if !ok || path == "" {
+		return fmt.Errorf("invalid Python source %q, expected conda:<env-path>", from)
+	}
+	switch kind {
+	case "conda":
+		return useCondaPython(projectPath, path, manifest, verbose)
+	default:
+		return fmt.Errorf("unsupported Python source %q, supported sources are: conda", kind)
+	}
+}
@@ -0,0 +1,123 @@
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gotray/got/internal/env"
+)
+
+// makeFakeConda creates the directory layout of a conda environment with the given files
+func makeFakeConda(t *testing.T, files ...string) string {
+	t.Helper()
+	prefix := t.TempDir()
+	for _, file := range files {
+		path := filepath.Join(prefix, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return prefix
+}
+
+func TestFindCondaPython(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake conda layout is for Unix")
+	}
+
+	t.Run("regular build", func(t *testing.T) {
+		prefix := makeFakeConda(t,
+			"conda-meta/python-3.12.7-h5148396_0.json",
+			"conda-meta/numpy-2.1.0-py312_0.json",
+			"bin/python3.12",
+			"bin/python3",
+			"lib/libpython3.12.so",
+			"include/python3.12/Python.h",
+		)
+		c, err := findCondaPython(prefix)
+		if err != nil {
+			t.Fatalf("findCondaPython() error = %v", err)
+		}
+		if c.Version != "3.12.7" || c.MajorMinor != "3.12" || c.ABI != "" {
+			t.Errorf("findCondaPython() version = %s/%s/%q, want 3.12.7/3.12/\"\"", c.Version, c.MajorMinor, c.ABI)
+		}
+		if want := filepath.Join(prefix, "bin", "python3.12"); c.Executable != want {
+			t.Errorf("findCondaPython() executable = %s, want %s", c.Executable, want)
+		}
+		if want := filepath.Join(prefix, "lib", "libpython3.12.so"); c.LibPython != want {
+			t.Errorf("findCondaPython() libpython = %s, want %s", c.LibPython, want)
+		}
+
+		// Without pkg-config files in the environment they are generated into the project
+		projectDir := t.TempDir()
+		dir, err := condaPkgConfigDir(projectDir, c)
+		if err != nil {
+			t.Fatalf("condaPkgConfigDir() error = %v", err)
+		}
+		if dir != env.GetPythonPkgConfigDir(projectDir) {
+			t.Errorf("condaPkgConfigDir() = %s, want project pkgconfig directory", dir)
+		}
+		content, err := os.ReadFile(filepath.Join(dir, "python3-embed.pc"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"prefix=" + prefix, "-lpython3.12", "includedir=" + filepath.Join(prefix, "include", "python3.12")} {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("python3-embed.pc does not contain %q:\n%s", want, content)
+			}
+		}
+	})
+
+	t.Run("free-threaded build with pkg-config files", func(t *testing.T) {
+		prefix := makeFakeConda(t,
+			"conda-meta/python-3.13.0-h9ebbce0_100_cp313t.json",
+			"conda-meta/python-freethreading-3.13.0-h92d6c8b_0.json",
+			"bin/python3.13t",
+			"lib/libpython3.13t.so",
+			"lib/pkgconfig/python-3.13t-embed.pc",
+			"lib/pkgconfig/python3-embed.pc",
+		)
+		c, err := findCondaPython(prefix)
+		if err != nil {
+			t.Fatalf("findCondaPython() error = %v", err)
+		}
+		if c.ABI != "t" || c.libName() != "python3.13t" {
+			t.Errorf("findCondaPython() ABI = %q, lib %s, want t, python3.13t", c.ABI, c.libName())
+		}
+		dir, err := condaPkgConfigDir(t.TempDir(), c)
+		if err != nil {
+			t.Fatalf("condaPkgConfigDir() error = %v", err)
+		}
+		if want := filepath.Join(prefix, "lib", "pkgconfig"); dir != want {
+			t.Errorf("condaPkgConfigDir() = %s, want %s", dir, want)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := findCondaPython(t.TempDir()); err == nil {
+			t.Error("findCondaPython() error = nil for a directory without conda-meta")
+		}
+		noPython := makeFakeConda(t, "conda-meta/numpy-2.1.0-py312_0.json")
+		if _, err := findCondaPython(noPython); err == nil {
+			t.Error("findCondaPython() error = nil for an environment without python")
+		}
+		noLib := makeFakeConda(t, "conda-meta/python-3.12.7-h5148396_0.json", "bin/python3")
+		if _, err := findCondaPython(noLib); err == nil || !strings.Contains(err.Error(), "libpython") {
+			t.Errorf("findCondaPython() error = %v, want libpython not found", err)
+		}
+	})
+}
+
+func TestUsePythonFrom(t *testing.T) {
+	for _, from := range []string{"conda", "conda:", "pyenv:/opt/pyenv"} {
+		if err := usePythonFrom(t.TempDir(), from, &env.Manifest{}, false); err == nil {
+			t.Errorf("usePythonFrom(%q) error = nil, want error", from)
+		}
+	}
+}
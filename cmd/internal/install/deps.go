@@ -9,19 +9,118 @@ import (
 	"github.com/gotray/got/internal/env"
 )
 
+// Options configures the dependencies installed by Dependencies
+type Options struct {
+	GoVersion    string
+	PyVersion    string
+	PyBuildDate  string
+	FreeThreaded bool
+	Debug        bool
+	Verbose      bool
+	// PythonPath selects an existing interpreter, or the prefix containing it,
+	// instead of downloading a python-build-standalone build
+	PythonPath string
+	// Libc selects the C library of Linux Python builds: auto, gnu or musl
+	Libc string
+	// Microarch selects the x86-64 level of linux/amd64 Python builds: auto, v1, v2, v3 or v4
+	Microarch string
+	// PythonFrom selects a Python managed by another tool, e.g. "conda:<env-path>"
+	PythonFrom string
+	// CToolchain selects the C compiler used by cgo: host or zig
+	CToolchain string
+	// ZigVersion selects the zig release installed for the zig C toolchain
+	ZigVersion string
+	// PythonProfile selects the parts of downloaded Python builds to install:
+	// minimal, standard or full (default)
+	PythonProfile string
+}
+
 // Dependencies installs all required dependencies for the project
-func Dependencies(projectPath string, goVersion, tinyPkgConfigVersion, pyVersion, pyBuildDate string, freeThreaded, debug bool, verbose bool) error {
-	if err := installTinyPkgConfig(projectPath, tinyPkgConfigVersion, verbose); err != nil {
+func Dependencies(projectPath string, opts Options) error {
+	goVersion, err := ResolveGoVersion(opts.GoVersion)
+	if err != nil {
 		return err
 	}
+	if opts.PythonPath != "" && opts.PythonFrom != "" {
+		return fmt.Errorf("--python and --python-from are mutually exclusive")
+	}
+	profile, err := newProfileFilter(opts.PythonProfile)
+	if err != nil {
+		return err
+	}
+	if (opts.PythonPath != "" || opts.PythonFrom != "") && profile.profile != PythonProfileFull {
+		return fmt.Errorf("--python-profile only applies to downloaded Python builds")
+	}
+	var pySpec pythonSpec
+	if opts.PythonPath == "" && opts.PythonFrom == "" {
+		libc, err := resolveLibc(opts.Libc, runtime.GOOS)
+		if err != nil {
+			return err
+		}
+		microarch, err := resolveMicroarch(opts.Microarch, runtime.GOOS, runtime.GOARCH)
+		if err != nil {
+			return err
+		}
+		pySpec = pythonSpec{
+			Arch:         runtime.GOARCH,
+			OS:           runtime.GOOS,
+			Libc:         libc,
+			Microarch:    microarch,
+			FreeThreaded: opts.FreeThreaded,
+			Debug:        opts.Debug,
+		}
+		if pySpec.Version, pySpec.BuildDate, err = resolvePythonBuild(opts.PyVersion, opts.PyBuildDate, pySpec); err != nil {
+			return err
+		}
+		// The release index is only used to refine the check, so validate without it when offline
+		releases, err := pythonReleasesFor(pySpec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping the release index check: %v\n", err)
+		}
+		if err := validatePythonSpec(pySpec, releases); err != nil {
+			return err
+		}
+	}
+
+	switch opts.CToolchain {
+	case "", CToolchainHost:
+	case CToolchainZig:
+		if runtime.GOOS == "windows" {
+			return fmt.Errorf("--c-toolchain zig is not supported on Windows, which uses MinGW")
+		}
+	default:
+		return fmt.Errorf("unknown C toolchain %q, expected host or zig", opts.CToolchain)
+	}
+
+	// A manifest from a previous installation would point the build environment at stale paths
+	if err := os.Remove(env.GetManifestPath(projectPath)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing existing manifest: %v", err)
+	}
+
 	// Only install MSYS2 on Windows
 	if runtime.GOOS == "windows" {
-		if err := installMingw(projectPath, verbose); err != nil {
+		if err := installMingw(projectPath, opts.Verbose); err != nil {
 			return err
 		}
 	}
 
-	if err := installGo(projectPath, goVersion, verbose); err != nil {
+	manifest := &env.Manifest{Go: env.GoManifest{Version: goVersion}}
+	if opts.CToolchain == CToolchainZig {
+		libc := pySpec.Libc
+		if libc == "" {
+			if libc, err = resolveLibc("auto", runtime.GOOS); err != nil {
+				return err
+			}
+		}
+		if manifest.Zig, err = installZig(projectPath, opts.ZigVersion, libc, opts.Verbose); err != nil {
+			return err
+		}
+	} else if err := os.RemoveAll(env.GetZigDir(projectPath)); err != nil {
+		// A zig from a previous installation would still be used as CC
+		return fmt.Errorf("error removing existing zig: %v", err)
+	}
+
+	if err := installGo(projectPath, goVersion, opts.Verbose); err != nil {
 		return err
 	}
 	env.SetBuildEnv(projectPath)
@@ -32,7 +131,19 @@ func Dependencies(projectPath string, goVersion, tinyPkgConfigVersion, pyVersion
 	}
 
 	// Install Python environment and dependencies
-	if err := installPythonEnv(projectPath, pyVersion, pyBuildDate, freeThreaded, debug, verbose); err != nil {
+	switch {
+	case opts.PythonPath != "":
+		err = useExternalPython(projectPath, opts.PythonPath, manifest, opts.Verbose)
+	case opts.PythonFrom != "":
+		err = usePythonFrom(projectPath, opts.PythonFrom, manifest, opts.Verbose)
+	default:
+		err = installPythonEnv(projectPath, pySpec, profile, manifest, opts.Verbose)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := env.WriteManifest(projectPath, manifest); err != nil {
 		return err
 	}
 
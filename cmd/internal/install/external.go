@@ -0,0 +1,133 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/gotray/got/internal/env"
+)
+
+// externalPythonEnv returns the Python environment for an interpreter path or
+// for the installation prefix containing it
+func externalPythonEnv(pythonPath string) (*env.PythonEnv, error) {
+	absPath, err := filepath.Abs(pythonPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %v", err)
+	}
+	fi, err := os.Stat(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("python not found: %v", err)
+	}
+	if fi.IsDir() {
+		exe, err := env.NewPythonEnv(absPath).Python()
+		if err != nil {
+			return nil, err
+		}
+		return &env.PythonEnv{Root: absPath, Exe: exe}, nil
+	}
+	root := filepath.Dir(absPath)
+	if runtime.GOOS != "windows" {
+		root = filepath.Dir(root)
+	}
+	return &env.PythonEnv{Root: root, Exe: absPath}, nil
+}
+
+// externalPkgConfigDir returns the directory with the interpreter's own
+// pkg-config files, generating them into the project from sysconfig if the
+// installation doesn't provide python3-embed.pc
+func externalPkgConfigDir(projectPath string, info *env.PythonInfo, home, libDir string) (string, error) {
+	if libPC := info.ConfigVar("LIBPC"); libPC != "" {
+		embed := filepath.Join(libPC, fmt.Sprintf("python-%s%s-embed.pc", info.MajorMinor, info.ABI()))
+		alias := filepath.Join(libPC, "python3-embed.pc")
+		if fileExists(embed) && fileExists(alias) {
+			return libPC, nil
+		}
+	}
+
+	spec := pcSpecFromInfo(info, filepath.ToSlash(home))
+	spec.LibDir = filepath.ToSlash(libDir)
+	if includeDir := info.ConfigVar("INCLUDEPY"); includeDir != "" {
+		spec.IncludeDir = filepath.ToSlash(includeDir)
+	}
+	if runtime.GOOS == "windows" {
+		spec.IncludeDir = "${prefix}/include"
+		spec.ExtLibName = "python3" + info.ABI()
+	}
+	spec.LibsPrivate = info.ConfigVar("LIBS")
+	spec.Static = info.LinkMode == "static"
+
+	pkgConfigDir := env.GetPythonPkgConfigDir(projectPath)
+	if err := writePythonPkgConfig(pkgConfigDir, spec); err != nil {
+		return "", err
+	}
+	return pkgConfigDir, nil
+}
+
+// useExternalPython configures the project to use an existing interpreter,
+// such as a distro, pyenv or internally built CPython, without downloading anything
+func useExternalPython(projectPath, pythonPath string, manifest *env.Manifest, verbose bool) error {
+	pyEnv, err := externalPythonEnv(pythonPath)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Using Python %s in %s\n", pyEnv.Exe, projectPath)
+
+	info, err := pyEnv.ProbeInfo()
+	if err != nil {
+		return fmt.Errorf("error running %s: %v", pyEnv.Exe, err)
+	}
+	// Record the real interpreter rather than a launcher such as a pyenv shim
+	if info.Exe != "" {
+		pyEnv.Exe = info.Exe
+	}
+
+	// PYTHONHOME must be the base installation, also when pointed at a virtualenv
+	home := info.ConfigVar("installed_base")
+	if home == "" {
+		home = info.ConfigVar("prefix")
+	}
+	if home == "" {
+		home = pyEnv.Root
+	}
+
+	libDir := info.ConfigVar("LIBDIR")
+	if info.LinkMode == "static" && info.ConfigVar("LIBPL") != "" {
+		// Static libpython is installed in the config directory
+		libDir = info.ConfigVar("LIBPL")
+		warnStaticLibPython(pyEnv.Exe + " (built without --enable-shared)")
+	}
+	if libDir == "" {
+		libDir = home
+	}
+	if verbose {
+		fmt.Printf("Python %s, PYTHONHOME %s, libpython in %s\n", info.Version, home, libDir)
+	}
+
+	pkgConfigDir, err := externalPkgConfigDir(projectPath, info, home, libDir)
+	if err != nil {
+		return err
+	}
+
+	pythonPathEnv, err := pyEnv.GetPythonPath()
+	if err != nil {
+		return fmt.Errorf("failed to get Python path: %v", err)
+	}
+	if err := env.WriteEnvFile(projectPath, home, pythonPathEnv); err != nil {
+		return fmt.Errorf("error writing environment file: %v", err)
+	}
+
+	manifest.Python = env.NewPythonManifest(info, "")
+	manifest.Python.Source = env.PythonSourceExternal
+	manifest.Python.Home = home
+	manifest.Python.Executable = pyEnv.Exe
+	manifest.Python.LibDir = libDir
+	manifest.Python.PkgConfigDir = pkgConfigDir
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -12,11 +12,13 @@ const (
 	goDownloadURL = "https://go.dev/dl/go%s.%s-%s.%s"
 )
 
-// getGoURL returns the appropriate Go download URL for the current platform
-func getGoURL(version string) string {
+// getGoURL returns the appropriate Go download URL for the platform.
+// The Linux toolchain is statically linked, so the same archive serves glibc
+// and musl systems; only cgo depends on the host C library.
+func getGoURL(version, goos, goarch string) string {
 	var os, arch, ext string
 
-	switch runtime.GOOS {
+	switch goos {
 	case "windows":
 		os = "windows"
 		ext = "zip"
@@ -30,7 +32,7 @@ func getGoURL(version string) string {
 		return ""
 	}
 
-	switch runtime.GOARCH {
+	switch goarch {
 	case "amd64":
 		arch = "amd64"
 	case "386":
@@ -49,7 +51,7 @@ func installGo(projectPath, version string, verbose bool) error {
 	goDir := env.GetGoDir(projectPath)
 	fmt.Printf("Installing Go %s in %s\n", version, goDir)
 	// Get download URL
-	url := getGoURL(version)
+	url := getGoURL(version, runtime.GOOS, runtime.GOARCH)
 	if url == "" {
 		return fmt.Errorf("unsupported platform")
 	}
@@ -0,0 +1,57 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	libcGNU  = "gnu"
+	libcMusl = "musl"
+)
+
+// muslLoaderPattern matches the dynamic loader installed on musl based systems such as Alpine
+var muslLoaderPattern = "/lib/ld-musl-*.so.1"
+
+// detectLibc returns the C library of the running Linux system
+func detectLibc() string {
+	if matches, _ := filepath.Glob(muslLoaderPattern); len(matches) > 0 {
+		return libcMusl
+	}
+	return libcGNU
+}
+
+// resolveLibc validates the --libc option for goos, detecting the C library
+// of the running system for "auto". The result is empty for non-Linux systems.
+func resolveLibc(libc, goos string) (string, error) {
+	switch libc {
+	case "", "auto":
+		if goos != "linux" || runtime.GOOS != "linux" {
+			return "", nil
+		}
+		return detectLibc(), nil
+	case libcGNU, "glibc":
+		if goos != "linux" {
+			return "", fmt.Errorf("--libc %s is only supported on Linux", libc)
+		}
+		return libcGNU, nil
+	case libcMusl:
+		if goos != "linux" {
+			return "", fmt.Errorf("--libc %s is only supported on Linux", libc)
+		}
+		return libcMusl, nil
+	default:
+		return "", fmt.Errorf("unknown libc %q, expected auto, gnu or musl", libc)
+	}
+}
+
+// warnStaticLibPython explains the consequences of a Python build without a shared libpython
+func warnStaticLibPython(info string) {
+	fmt.Fprintf(os.Stderr, `Warning: %s links libpython statically.
+  cgo links libpython into every binary instead of loading it at run time, so
+  builds are larger and need the static library's dependencies on the linker
+  command line. Compiled extension modules installed with pip cannot be loaded.
+`, info)
+}
@@ -0,0 +1,85 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// cpuInfoPath is read to detect the CPU features of the running system
+var cpuInfoPath = "/proc/cpuinfo"
+
+// microarchLevels lists the x86-64 microarchitecture levels with the
+// /proc/cpuinfo flags each one adds to the previous level
+var microarchLevels = []struct {
+	level string
+	flags []string
+}{
+	{"v2", []string{"cx16", "lahf_lm", "popcnt", "sse4_1", "sse4_2", "ssse3"}},
+	{"v3", []string{"avx", "avx2", "bmi1", "bmi2", "f16c", "fma", "abm", "movbe", "xsave"}},
+	{"v4", []string{"avx512f", "avx512bw", "avx512cd", "avx512dq", "avx512vl"}},
+}
+
+// microarchFromCPUInfo returns the highest x86-64 level ("v1" to "v4")
+// supported according to the flags in /proc/cpuinfo content
+func microarchFromCPUInfo(cpuinfo string) string {
+	flags := map[string]bool{}
+	for _, line := range strings.Split(cpuinfo, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) != "flags" {
+			continue
+		}
+		for _, flag := range strings.Fields(value) {
+			flags[flag] = true
+		}
+		// All cores report the same flags
+		break
+	}
+
+	level := "v1"
+	for _, l := range microarchLevels {
+		for _, flag := range l.flags {
+			if !flags[flag] {
+				return level
+			}
+		}
+		level = l.level
+	}
+	return level
+}
+
+// resolveMicroarch validates the --python-microarch option for the target,
+// detecting the CPU of the running system for "auto". The result is empty for
+// the portable baseline build.
+func resolveMicroarch(microarch, goos, goarch string) (string, error) {
+	switch microarch {
+	case "", "v1", "baseline":
+		return "", nil
+	case "auto":
+		if goos != "linux" || goarch != "amd64" {
+			return "", nil
+		}
+		// The CPU of another target can't be detected here
+		if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+			return "", nil
+		}
+		content, err := os.ReadFile(cpuInfoPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to detect CPU features: %v", err)
+		}
+		level := microarchFromCPUInfo(string(content))
+		if level == "v1" {
+			return "", nil
+		}
+		fmt.Printf("Detected x86-64-%s CPU, binaries will only run on CPUs supporting it (use --python-microarch v1 for portable builds)\n", level)
+		return level, nil
+	case "v2", "v3", "v4":
+		if goos != "linux" || goarch != "amd64" {
+			return "", fmt.Errorf("--python-microarch %s is only available for linux/amd64", microarch)
+		}
+		return microarch, nil
+	default:
+		return "", fmt.Errorf("unknown microarchitecture %q, expected auto, v1, v2, v3 or v4", microarch)
+	}
+}
@@ -0,0 +1,74 @@
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+const (
+	cpuFlagsV2 = "fpu sse sse2 ssse3 cx16 sse4_1 sse4_2 popcnt lahf_lm"
+	cpuFlagsV3 = cpuFlagsV2 + " avx avx2 bmi1 bmi2 f16c fma abm movbe xsave"
+	cpuFlagsV4 = cpuFlagsV3 + " avx512f avx512bw avx512cd avx512dq avx512vl"
+)
+
+func TestMicroarchFromCPUInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		cpuinfo string
+		want    string
+	}{
+		{"empty", "", "v1"},
+		{"baseline", "processor\t: 0\nflags\t\t: fpu sse sse2\n", "v1"},
+		{"v2", "processor\t: 0\nflags\t\t: " + cpuFlagsV2 + "\n", "v2"},
+		{"v3", "processor\t: 0\nflags\t\t: " + cpuFlagsV3 + "\nbugs\t\t: spectre_v1\n", "v3"},
+		{"v4", "flags\t\t: " + cpuFlagsV4 + "\n\nprocessor\t: 1\nflags\t\t: " + cpuFlagsV4 + "\n", "v4"},
+		{"v4 flags without v3", "flags\t\t: " + cpuFlagsV2 + " avx512f avx512bw avx512cd avx512dq avx512vl\n", "v2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := microarchFromCPUInfo(tt.cpuinfo); got != tt.want {
+				t.Errorf("microarchFromCPUInfo() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveMicroarch(t *testing.T) {
+	tests := []struct {
+		microarch, goos, goarch string
+		want                    string
+		wantErr                 bool
+	}{
+		{microarch: "v1", goos: "linux", goarch: "amd64", want: ""},
+		{microarch: "v3", goos: "linux", goarch: "amd64", want: "v3"},
+		{microarch: "auto", goos: "darwin", goarch: "arm64", want: ""},
+		{microarch: "v3", goos: "linux", goarch: "arm64", wantErr: true},
+		{microarch: "v5", goos: "linux", goarch: "amd64", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := resolveMicroarch(tt.microarch, tt.goos, tt.goarch)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolveMicroarch(%q) error = %v, wantErr %v", tt.microarch, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resolveMicroarch(%q) = %q, want %q", tt.microarch, got, tt.want)
+		}
+	}
+
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		return
+	}
+	cpuinfo := filepath.Join(t.TempDir(), "cpuinfo")
+	if err := os.WriteFile(cpuinfo, []byte("flags\t\t: "+cpuFlagsV3+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	orig := cpuInfoPath
+	cpuInfoPath = cpuinfo
+	defer func() { cpuInfoPath = orig }()
+	if got, err := resolveMicroarch("auto", "linux", "amd64"); err != nil || got != "v3" {
+		t.Errorf("resolveMicroarch(auto) = %q, %v, want v3", got, err)
+	}
+}
@@ -0,0 +1,153 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gotray/got/internal/env"
+)
+
+// pcSpec describes the pkg-config files generated for a Python installation
+type pcSpec struct {
+	Prefix      string // value of the prefix variable
+	LibDir      string // value of the libdir variable, may reference ${exec_prefix}
+	IncludeDir  string // value of the includedir variable, may reference ${prefix}
+	Version     string // major.minor version
+	ABI         string // ABI flags, e.g. "t" for free-threaded builds
+	LibName     string // library linked when embedding, e.g. "python3.13"
+	ExtLibName  string // library linked by extension modules, empty if none
+	LibsPrivate string // libraries libpython depends on when linked statically
+	Static      bool   // libpython is only available as a static library
+	StaticLib   string // path of the static libpython linked instead of -l LibName
+}
+
+// pcSpecFromInfo derives pkg-config settings from a Python build description
+func pcSpecFromInfo(info *env.PythonInfo, prefix string) pcSpec {
+	spec := pcSpec{
+		Prefix:     prefix,
+		LibDir:     "${exec_prefix}/lib",
+		IncludeDir: "${prefix}/include/python" + info.MajorMinor + info.ABI(),
+		Version:    info.MajorMinor,
+		ABI:        info.ABI(),
+		LibName:    info.LibName(),
+	}
+	var private []string
+	for _, link := range info.BuildInfo.Core.Links {
+		if link.Framework {
+			private = append(private, "-framework", link.Name)
+		} else if link.System {
+			private = append(private, "-l"+link.Name)
+		}
+	}
+	spec.LibsPrivate = strings.Join(private, " ")
+	return spec
+}
+
+const pcTemplate = `prefix=%s
+exec_prefix=${prefix}
+libdir=%s
+includedir=%s
+
+Name: Python
+Description: %s
+Requires:
+Version: %s
+Libs.private: %s
+Libs: %s
+Cflags: -I${includedir}
+`
+
+// writePythonPkgConfig writes python-X.Y.pc, python-X.Y-embed.pc and their
+// python3 aliases into dir. Builds with ABI flags also get the flagged names.
+func writePythonPkgConfig(dir string, spec pcSpec) error {
+	fmt.Printf("Generating pkg-config files in %s\n", dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create pkgconfig directory: %v", err)
+	}
+
+	libs := "-L${libdir}"
+	if spec.ExtLibName != "" {
+		libs += " -l" + spec.ExtLibName
+	}
+	embedLibs := "-L${libdir} -l" + spec.LibName
+	if spec.StaticLib != "" {
+		embedLibs = spec.StaticLib
+	}
+	if spec.Static && spec.LibsPrivate != "" {
+		// Without a shared libpython its dependencies must always be linked
+		embedLibs += " " + spec.LibsPrivate
+	}
+	normal := fmt.Sprintf(pcTemplate, spec.Prefix, spec.LibDir, spec.IncludeDir,
+		"Python library", spec.Version, spec.LibsPrivate, libs)
+	embed := fmt.Sprintf(pcTemplate, spec.Prefix, spec.LibDir, spec.IncludeDir,
+		"Embed Python into an application", spec.Version, spec.LibsPrivate, embedLibs)
+
+	abis := []string{spec.ABI}
+	if spec.ABI != "" {
+		abis = append(abis, "")
+	}
+	for _, abi := range abis {
+		files := map[string]string{
+			fmt.Sprintf("python-%s%s.pc", spec.Version, abi):       normal,
+			fmt.Sprintf("python-%s%s-embed.pc", spec.Version, abi): embed,
+			"python3" + abi + ".pc":                                normal,
+			"python3" + abi + "-embed.pc":                          embed,
+		}
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %v", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Regular expressions for matching file patterns of builds without PYTHON.json
+var (
+	normalPCPattern = regexp.MustCompile(`^python-(\d+\.\d+)t?\.pc$`)
+	embedPCPattern  = regexp.MustCompile(`^python-(\d+\.\d+)t?-embed\.pc$`)
+)
+
+// pkgConfigAliases returns the names under which the pkg-config file name is
+// also made available. With build info only the files of the installed ABI
+// get aliases; otherwise the ABI is guessed from the file name.
+func pkgConfigAliases(name string, info *env.PythonInfo) []string {
+	if info != nil {
+		mm, abi := info.MajorMinor, info.ABI()
+		for _, suffix := range []string{"", "-embed"} {
+			if name != "python-"+mm+abi+suffix+".pc" {
+				continue
+			}
+			aliases := []string{"python3" + abi + suffix + ".pc"}
+			if abi != "" {
+				aliases = append(aliases, "python-"+mm+suffix+".pc", "python3"+suffix+".pc")
+			}
+			return aliases
+		}
+		return nil
+	}
+
+	// Handle python-X.YZt.pc and python-X.YZ.pc patterns
+	if matches := normalPCPattern.FindStringSubmatch(name); matches != nil {
+		if strings.HasSuffix(name, "t.pc") {
+			// python-3.13t.pc -> python3t.pc, python3.pc and python-3.13.pc
+			return []string{"python3t.pc", "python3.pc", fmt.Sprintf("python-%s.pc", matches[1])}
+		}
+		// python-3.13.pc -> python3.pc
+		return []string{"python3.pc"}
+	}
+
+	// Handle python-X.YZt-embed.pc and python-X.YZ-embed.pc patterns
+	if matches := embedPCPattern.FindStringSubmatch(name); matches != nil {
+		if strings.HasSuffix(name, "t-embed.pc") {
+			// python-3.13t-embed.pc -> python3t-embed.pc, python3-embed.pc and python-3.13-embed.pc
+			return []string{"python3t-embed.pc", "python3-embed.pc", fmt.Sprintf("python-%s-embed.pc", matches[1])}
+		}
+		// python-3.13-embed.pc -> python3-embed.pc
+		return []string{"python3-embed.pc"}
+	}
+	return nil
+}
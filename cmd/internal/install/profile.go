@@ -0,0 +1,185 @@
+package install
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/gotray/got/internal/env"
+)
+
+// Python install profiles of got init --python-profile
+const (
+	// PythonProfileMinimal leaves out the test suite, IDLE, Tk, lib2to3,
+	// ensurepip, static libraries, debug info and man pages
+	PythonProfileMinimal = "minimal"
+	// PythonProfileStandard leaves out the test suite
+	PythonProfileStandard = "standard"
+	// PythonProfileFull installs the whole python-build-standalone tree
+	PythonProfileFull = "full"
+)
+
+// stdlibPattern matches the standard library directory of the install tree
+var stdlibPattern = regexp.MustCompile(`^(lib/python3\.\d+t?|Lib)/`)
+
+var (
+	// testDirNames are the directories of tests in the standard library
+	testDirNames = map[string]bool{"test": true, "tests": true, "idle_test": true}
+	// minimalStdlibExcludes are standard library modules applications rarely need
+	minimalStdlibExcludes = map[string]bool{
+		"idlelib": true, "tkinter": true, "turtledemo": true, "lib2to3": true,
+		"ensurepip": true, "turtle.py": true,
+	}
+	// tkLibPattern matches the Tcl/Tk libraries and scripts of the install tree
+	tkLibPattern = regexp.MustCompile(`^(lib/(tcl|tk|itcl|thread|libtcl|libtk)[^/]*|tcl)(/|$)`)
+)
+
+// profileFilter selects the entries of the install tree of a profile and
+// records what it leaves out
+type profileFilter struct {
+	profile  string
+	excluded map[string]bool
+}
+
+func newProfileFilter(profile string) (*profileFilter, error) {
+	switch profile {
+	case "":
+		profile = PythonProfileFull
+	case PythonProfileMinimal, PythonProfileStandard, PythonProfileFull:
+	default:
+		return nil, fmt.Errorf("unknown Python profile %q, expected minimal, standard or full", profile)
+	}
+	return &profileFilter{profile: profile, excluded: map[string]bool{}}, nil
+}
+
+// skip reports whether to leave out the entry name of the install tree, a
+// hard link to link if not empty, and records the excluded file or directory.
+// Hard links to excluded files are excluded too.
+func (f *profileFilter) skip(name, link string) bool {
+	if f.profile == PythonProfileFull {
+		return false
+	}
+	name = strings.TrimSuffix(name, "/")
+	if excluded := f.match(name); excluded != "" {
+		f.excluded[excluded] = true
+		return true
+	}
+	if link != "" && f.match(link) != "" {
+		f.excluded[name] = true
+		return true
+	}
+	return false
+}
+
+// match returns the excluded file or directory containing name, or an
+// empty string if the profile keeps it
+func (f *profileFilter) match(name string) string {
+	if m := stdlibPattern.FindString(name + "/"); m != "" && len(m) <= len(name) {
+		rel := strings.TrimPrefix(name, m)
+		parts := strings.Split(rel, "/")
+		if parts[0] == "site-packages" {
+			return ""
+		}
+		if f.profile == PythonProfileMinimal && minimalStdlibExcludes[parts[0]] {
+			return m + parts[0]
+		}
+		for i, part := range parts {
+			if testDirNames[part] {
+				return m + strings.Join(parts[:i+1], "/")
+			}
+		}
+		if f.profile != PythonProfileMinimal {
+			return ""
+		}
+		if len(parts) == 2 && parts[0] == "lib-dynload" && strings.HasPrefix(parts[1], "_tkinter") {
+			return name
+		}
+		if isDebugInfo(name) {
+			return name
+		}
+		return ""
+	}
+	if f.profile != PythonProfileMinimal {
+		return ""
+	}
+	if m := tkLibPattern.FindStringSubmatch(name); m != nil {
+		return m[1]
+	}
+	if strings.HasPrefix(name, "DLLs/_tkinter") || strings.HasPrefix(name, "DLLs/tcl") || strings.HasPrefix(name, "DLLs/tk") {
+		return name
+	}
+	if name == "share" || strings.HasPrefix(name, "share/") {
+		return "share"
+	}
+	if isDebugInfo(name) {
+		return name
+	}
+	return ""
+}
+
+// isDebugInfo reports whether name is a static library or debug info
+func isDebugInfo(name string) bool {
+	ext := path.Ext(name)
+	return ext == ".a" || ext == ".pdb" || strings.HasSuffix(name, ".dSYM") || strings.Contains(name, ".dSYM/")
+}
+
+// Excluded returns the files and directories left out, sorted
+func (f *profileFilter) Excluded() []string {
+	excluded := make([]string, 0, len(f.excluded))
+	for name := range f.excluded {
+		excluded = append(excluded, name)
+	}
+	sort.Strings(excluded)
+	return excluded
+}
+
+// RestorePython installs the parts of the project's Python its install
+// profile left out and records the installation as full
+func RestorePython(projectPath string, verbose bool) error {
+	m, err := env.ReadManifest(projectPath)
+	if err != nil {
+		return fmt.Errorf("restoring Python needs the manifest of the project's Python: %v", err)
+	}
+	if len(m.Python.Excluded) == 0 {
+		fmt.Println("Nothing to restore, the project's Python is complete")
+		return nil
+	}
+	spec, err := targetSpec(projectPath, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+	spec.Microarch = m.Python.Microarch
+	url := getPythonURL(spec)
+	if url == "" {
+		return fmt.Errorf("no Python build for %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+
+	// Extract only what the profile left out
+	excluded := m.Python.Excluded
+	skip := func(name, link string) bool {
+		name = strings.TrimSuffix(name, "/")
+		for _, e := range excluded {
+			if name == e || strings.HasPrefix(name, e+"/") {
+				return false
+			}
+		}
+		return true
+	}
+	fmt.Printf("Restoring %d parts of Python %s left out by the %s profile\n", len(excluded), m.Python.Version, m.Python.Profile)
+	pythonRoot := env.GetPythonRoot(projectPath)
+	if err := downloadAndExtractFiltered("Python", spec.Version, url, pythonRoot, "python/install", skip, verbose); err != nil {
+		return fmt.Errorf("error downloading and extracting Python: %v", err)
+	}
+	if runtime.GOOS == "darwin" {
+		if err := updateMacOSDylibs(pythonRoot, verbose); err != nil {
+			return fmt.Errorf("error updating dylib install names: %v", err)
+		}
+	}
+
+	m.Python.Profile = PythonProfileFull
+	m.Python.Excluded = nil
+	return env.WriteManifest(projectPath, m)
+}
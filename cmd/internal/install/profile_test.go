@@ -0,0 +1,136 @@
+package install
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestProfileFilter(t *testing.T) {
+	names := []string{
+		"bin/python3.13",
+		"lib/libpython3.13.so.1.0",
+		"lib/libpython3.13.a",
+		"lib/python3.13/os.py",
+		"lib/python3.13/test/",
+		"lib/python3.13/test/test_os.py",
+		"lib/python3.13/json/tests/test_dump.py",
+		"lib/python3.13/idlelib/idle_test/test_run.py",
+		"lib/python3.13/site-packages/pkg/tests/test_pkg.py",
+		"lib/python3.13/tkinter/__init__.py",
+		"lib/python3.13/turtle.py",
+		"lib/python3.13/lib-dynload/_tkinter.cpython-313-x86_64-linux-gnu.so",
+		"lib/python3.13/lib-dynload/_json.cpython-313-x86_64-linux-gnu.so",
+		"lib/python3.13/config-3.13-x86_64-linux-gnu/libpython3.13.a",
+		"lib/tcl8.6/init.tcl",
+		"lib/libtcl8.6.so",
+		"share/man/man1/python3.13.1",
+		"Lib/test/test_os.py",
+		"DLLs/_tkinter.pyd",
+		"python313.pdb",
+		"PYTHON.json",
+	}
+	tests := []struct {
+		profile string
+		want    []string
+	}{
+		{PythonProfileFull, []string{}},
+		{"", []string{}},
+		{PythonProfileStandard, []string{
+			"Lib/test",
+			"lib/python3.13/idlelib/idle_test",
+			"lib/python3.13/json/tests",
+			"lib/python3.13/test",
+		}},
+		{PythonProfileMinimal, []string{
+			"DLLs/_tkinter.pyd",
+			"Lib/test",
+			"lib/libpython3.13.a",
+			"lib/libtcl8.6.so",
+			"lib/python3.13/config-3.13-x86_64-linux-gnu/libpython3.13.a",
+			"lib/python3.13/idlelib",
+			"lib/python3.13/json/tests",
+			"lib/python3.13/lib-dynload/_tkinter.cpython-313-x86_64-linux-gnu.so",
+			"lib/python3.13/test",
+			"lib/python3.13/tkinter",
+			"lib/python3.13/turtle.py",
+			"lib/tcl8.6",
+			"python313.pdb",
+			"share",
+		}},
+	}
+	for _, tt := range tests {
+		f, err := newProfileFilter(tt.profile)
+		if err != nil {
+			t.Fatalf("newProfileFilter(%q) error = %v", tt.profile, err)
+		}
+		for _, name := range names {
+			f.skip(name, "")
+		}
+		if got := f.Excluded(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("profile %q excluded %q, want %q", tt.profile, got, tt.want)
+		}
+	}
+
+	if _, err := newProfileFilter("tiny"); err == nil {
+		t.Error("newProfileFilter(tiny) should fail")
+	}
+}
+
+func TestExtractTarZstSkip(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "python.tar.zst")
+	file, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := zstd.NewWriter(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(enc)
+	for _, name := range []string{"python/install/lib/python3.13/os.py", "python/install/lib/python3.13/test/test_os.py"} {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(name)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A hard link to a skipped file is skipped and recorded too
+	if err := tw.WriteHeader(&tar.Header{Name: "python/install/bin/test_os.py", Linkname: "python/install/lib/python3.13/test/test_os.py", Typeflag: tar.TypeLink}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: "python/install/bin/os.py", Linkname: "python/install/lib/python3.13/os.py", Typeflag: tar.TypeLink}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	f, _ := newProfileFilter(PythonProfileStandard)
+	dst := filepath.Join(dir, "out")
+	if err := extractTarZst(archive, dst, "python/install", f.skip, false); err != nil {
+		t.Fatalf("extractTarZst() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "lib", "python3.13", "os.py")); err != nil {
+		t.Errorf("os.py was not extracted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "lib", "python3.13", "test")); !os.IsNotExist(err) {
+		t.Errorf("test directory was extracted, Stat() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "bin", "os.py")); err != nil {
+		t.Errorf("hard link to os.py was not extracted: %v", err)
+	}
+	if got, want := f.Excluded(), []string{"bin/test_os.py", "lib/python3.13/test"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Excluded() = %q, want %q", got, want)
+	}
+}
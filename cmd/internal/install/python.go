@@ -5,7 +5,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"runtime"
 	"strings"
 
@@ -25,30 +24,50 @@ type pythonBuild struct {
 	fullPack bool
 }
 
-// getPythonURL returns the appropriate Python standalone URL for the current platform
-func getPythonURL(version, buildDate, arch, os string, freeThreaded, debug bool) string {
-	// Map GOARCH to Python build architecture
-	archMap := map[string]string{
-		"amd64": "x86_64",
-		"arm64": "aarch64",
-		"386":   "i686",
-	}
+// pythonSpec describes the python-build-standalone archive to install
+type pythonSpec struct {
+	Version      string
+	BuildDate    string
+	Arch         string // GOARCH of the target
+	OS           string // GOOS of the target
+	Libc         string // "gnu" or "musl", Linux only; empty means gnu
+	Microarch    string // x86-64 level "v2", "v3" or "v4", linux/amd64 only; empty means baseline
+	FreeThreaded bool
+	Debug        bool
+}
+
+// pythonArchMap maps GOARCH to python-build-standalone architectures
+var pythonArchMap = map[string]string{
+	"amd64": "x86_64",
+	"arm64": "aarch64",
+	"386":   "i686",
+}
 
-	pythonArch, ok := archMap[arch]
+// getPythonFilename returns the python-build-standalone archive name for spec,
+// or an empty string if the platform is not supported
+func getPythonFilename(spec pythonSpec) string {
+	pythonArch, ok := pythonArchMap[spec.Arch]
 	if !ok {
 		return ""
 	}
 
+	if spec.Microarch != "" {
+		if spec.OS != "linux" || spec.Arch != "amd64" {
+			return ""
+		}
+		pythonArch += "_" + spec.Microarch
+	}
+
 	build := pythonBuild{
 		arch:     pythonArch,
 		fullPack: true,
-		debug:    debug,
+		debug:    spec.Debug,
 	}
 
-	switch os {
+	switch spec.OS {
 	case "darwin":
 		build.os = "apple-darwin"
-		if freeThreaded {
+		if spec.FreeThreaded {
 			build.variant = "freethreaded"
 			if build.debug {
 				build.variant += "+debug"
@@ -64,24 +83,34 @@ func getPythonURL(version, buildDate, arch, os string, freeThreaded, debug bool)
 		}
 	case "linux":
 		build.os = "unknown-linux-gnu"
-		if freeThreaded {
+		// musl builds are not profile guided, only link time optimized
+		optimized := "pgo"
+		switch spec.Libc {
+		case "", libcGNU:
+		case libcMusl:
+			build.os = "unknown-linux-musl"
+			optimized = "lto"
+		default:
+			return ""
+		}
+		if spec.FreeThreaded {
 			build.variant = "freethreaded"
 			if build.debug {
 				build.variant += "+debug"
 			} else {
-				build.variant += "+pgo"
+				build.variant += "+" + optimized
 			}
 		} else {
 			if build.debug {
 				build.variant = "debug"
 			} else {
-				build.variant = "pgo"
+				build.variant = optimized
 			}
 		}
 	case "windows":
 		build.os = "pc-windows-msvc"
 		build.shared = true
-		if freeThreaded {
+		if spec.FreeThreaded {
 			build.variant = "freethreaded+pgo"
 		} else {
 			build.variant = "pgo"
@@ -91,7 +120,7 @@ func getPythonURL(version, buildDate, arch, os string, freeThreaded, debug bool)
 	}
 
 	// Construct filename
-	filename := fmt.Sprintf("cpython-%s+%s-%s-%s", version, buildDate, build.arch, build.os)
+	filename := fmt.Sprintf("cpython-%s+%s-%s-%s", spec.Version, spec.BuildDate, build.arch, build.os)
 	if build.shared {
 		filename += "-shared"
 	}
@@ -100,8 +129,16 @@ func getPythonURL(version, buildDate, arch, os string, freeThreaded, debug bool)
 		filename += "-full"
 	}
 	filename += ".tar.zst"
+	return filename
+}
 
-	return fmt.Sprintf(baseURL, buildDate) + "/" + filename
+// getPythonURL returns the appropriate Python standalone URL for spec
+func getPythonURL(spec pythonSpec) string {
+	filename := getPythonFilename(spec)
+	if filename == "" {
+		return ""
+	}
+	return fmt.Sprintf(baseURL, spec.BuildDate) + "/" + filename
 }
 
 // updateMacOSDylibs updates the install names of dylib files on macOS
@@ -155,146 +192,57 @@ func updateMacOSDylibs(pythonDir string, verbose bool) error {
 	return nil
 }
 
-// genWinPyPkgConfig generates pkg-config files for Windows
-func genWinPyPkgConfig(pythonRoot, pkgConfigDir string) error {
-	fmt.Printf("Generating pkg-config files in %s\n", pkgConfigDir)
-	if err := os.MkdirAll(pkgConfigDir, 0755); err != nil {
-		return fmt.Errorf("failed to create pkgconfig directory: %v", err)
-	}
-
-	// Get Python environment
+// pythonInfo returns the build description of the Python installation at
+// pythonRoot, probing the interpreter when no PYTHON.json was shipped
+func pythonInfo(pythonRoot string) (*env.PythonInfo, error) {
 	pyEnv := env.NewPythonEnv(pythonRoot)
-	pythonBin, err := pyEnv.Python()
-	if err != nil {
-		return fmt.Errorf("failed to get Python executable: %v", err)
-	}
-
-	// Get Python version and check if freethreaded
-	cmd := exec.Command(pythonBin, "-c", `
-import sys
-import sysconfig
-version = f'{sys.version_info.major}.{sys.version_info.minor}'
-is_freethreaded = hasattr(sys, "gettotalrefcount")
-print(f'{version}\n{is_freethreaded}')
-`)
-	output, err := cmd.Output()
-	if err != nil {
-		return fmt.Errorf("failed to get Python info: %v", err)
-	}
-
-	info := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(info) != 2 {
-		return fmt.Errorf("unexpected Python info output format")
-	}
-
-	version := strings.TrimSpace(info[0])
-	isFreethreaded := strings.TrimSpace(info[1]) == "True"
-
-	// Prepare version-specific library names
-	versionNoPoints := strings.ReplaceAll(version, ".", "")
-	libSuffix := ""
-	if isFreethreaded {
-		libSuffix = "t"
-	}
-
-	// Template for the pkg-config files
-	embedTemplate := `prefix=${pcfiledir}/../..
-exec_prefix=${prefix}
-libdir=${exec_prefix}
-includedir=${prefix}/include
-
-Name: Python
-Description: Embed Python into an application
-Requires:
-Version: %s
-Libs.private:
-Libs: -L${libdir} -lpython%s%s
-Cflags: -I${includedir}
-`
-
-	normalTemplate := `prefix=${pcfiledir}/../..
-exec_prefix=${prefix}
-libdir=${exec_prefix}
-includedir=${prefix}/include
-
-Name: Python
-Description: Python library
-Requires:
-Version: %s
-Libs.private:
-Libs: -L${libdir} -lpython3%s
-Cflags: -I${includedir}
-`
-
-	// Generate file pairs
-	filePairs := []struct {
-		name     string
-		template string
-		embed    bool
-	}{
-		{fmt.Sprintf("python-%s%s.pc", version, libSuffix), normalTemplate, false},
-		{fmt.Sprintf("python-%s%s-embed.pc", version, libSuffix), embedTemplate, true},
-		{"python3" + libSuffix + ".pc", normalTemplate, false},
-		{"python3" + libSuffix + "-embed.pc", embedTemplate, true},
-	}
-
-	// If freethreaded, also generate non-t versions with the same content
-	if isFreethreaded {
-		additionalPairs := []struct {
-			name     string
-			template string
-			embed    bool
-		}{
-			{fmt.Sprintf("python-%s.pc", version), normalTemplate, false},
-			{fmt.Sprintf("python-%s-embed.pc", version), embedTemplate, true},
-			{"python3.pc", normalTemplate, false},
-			{"python3-embed.pc", embedTemplate, true},
-		}
-		filePairs = append(filePairs, additionalPairs...)
-	}
-
-	// Write all pkg-config files
-	for _, pair := range filePairs {
-		pcPath := filepath.Join(pkgConfigDir, pair.name)
-		var content string
-		if pair.embed {
-			content = fmt.Sprintf(pair.template, version, versionNoPoints, libSuffix)
-		} else {
-			content = fmt.Sprintf(pair.template, version, libSuffix)
-		}
-		if err := os.WriteFile(pcPath, []byte(content), 0644); err != nil {
-			return fmt.Errorf("failed to write %s: %v", pair.name, err)
-		}
+	if info, err := pyEnv.Info(); err == nil {
+		return info, nil
 	}
+	return pyEnv.ProbeInfo()
+}
 
-	return nil
+// genWinPyPkgConfig generates pkg-config files for Windows
+func genWinPyPkgConfig(info *env.PythonInfo, pkgConfigDir string) error {
+	spec := pcSpecFromInfo(info, "${pcfiledir}/../..")
+	// Windows builds keep the DLL and import library in the root directory
+	spec.LibDir = "${exec_prefix}"
+	spec.IncludeDir = "${prefix}/include"
+	spec.ExtLibName = "python3" + info.ABI()
+	spec.LibsPrivate = ""
+	return writePythonPkgConfig(pkgConfigDir, spec)
 }
 
 // updatePkgConfig updates the prefix in pkg-config files to use absolute path
 func updatePkgConfig(projectPath string) error {
 	pythonPath := env.GetPythonRoot(projectPath)
-	pkgConfigDir := env.GetPythonPkgConfigDir(projectPath)
+	absPath, err := filepath.Abs(pythonPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %v", err)
+	}
 
-	entries, err := os.ReadDir(pkgConfigDir)
+	// Build info tells which ABI was installed, without it aliases are derived from file names
+	info, err := env.NewPythonEnv(pythonPath).Info()
 	if err != nil {
-		return fmt.Errorf("failed to read pkgconfig directory: %v", err)
+		info = nil
 	}
+	return rewritePkgConfig(env.GetPythonPkgConfigDir(projectPath), absPath, info)
+}
 
-	absPath, err := filepath.Abs(pythonPath)
+// rewritePkgConfig replaces the /install prefix of the python-build-standalone
+// pkg-config files in pkgConfigDir with prefix and writes their aliases
+func rewritePkgConfig(pkgConfigDir, prefix string, info *env.PythonInfo) error {
+	entries, err := os.ReadDir(pkgConfigDir)
 	if err != nil {
-		return fmt.Errorf("failed to get absolute path: %v", err)
+		return fmt.Errorf("failed to read pkgconfig directory: %v", err)
 	}
 
 	// Helper function to write a .pc file with the correct prefix
 	writePC := func(path string, content []byte) error {
-		newContent := strings.ReplaceAll(string(content), "prefix=/install", "prefix="+absPath)
+		newContent := strings.ReplaceAll(string(content), "prefix=/install", "prefix="+prefix)
 		return os.WriteFile(path, []byte(newContent), 0644)
 	}
 
-	// Regular expressions for matching file patterns
-	normalPattern := regexp.MustCompile(`^python-(\d+\.\d+)t?\.pc$`)
-	embedPattern := regexp.MustCompile(`^python-(\d+\.\d+)t?-embed\.pc$`)
-
 	for _, entry := range entries {
 		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".pc") {
 			pcFile := filepath.Join(pkgConfigDir, entry.Name())
@@ -310,49 +258,11 @@ func updatePkgConfig(projectPath string) error {
 				return fmt.Errorf("failed to update %s: %v", pcFile, err)
 			}
 
-			name := entry.Name()
-			// Create additional copies based on patterns
-			copies := make(map[string]bool)
-
-			// Handle python-X.YZt.pc and python-X.YZ.pc patterns
-			if matches := normalPattern.FindStringSubmatch(name); matches != nil {
-				if strings.Contains(name, "t.pc") {
-					// python-3.13t.pc -> python3.pc and python3t.pc
-					copies["python3t.pc"] = true
-					copies["python3.pc"] = true
-					// Also create non-t version
-					noT := fmt.Sprintf("python-%s.pc", matches[1])
-					if err := writePC(filepath.Join(pkgConfigDir, noT), content); err != nil {
-						return fmt.Errorf("failed to write %s: %v", noT, err)
-					}
-				} else {
-					// python-3.13.pc -> python3.pc
-					copies["python3.pc"] = true
-				}
-			}
-
-			// Handle python-X.YZt-embed.pc and python-X.YZ-embed.pc patterns
-			if matches := embedPattern.FindStringSubmatch(name); matches != nil {
-				if strings.Contains(name, "t-embed.pc") {
-					// python-3.13t-embed.pc -> python3-embed.pc and python3t-embed.pc
-					copies["python3t-embed.pc"] = true
-					copies["python3-embed.pc"] = true
-					// Also create non-t version
-					noT := fmt.Sprintf("python-%s-embed.pc", matches[1])
-					if err := writePC(filepath.Join(pkgConfigDir, noT), content); err != nil {
-						return fmt.Errorf("failed to write %s: %v", noT, err)
-					}
-				} else {
-					// python-3.13-embed.pc -> python3-embed.pc
-					copies["python3-embed.pc"] = true
-				}
-			}
-
-			// Write all unique copies
-			for copyName := range copies {
-				copyPath := filepath.Join(pkgConfigDir, copyName)
-				if err := writePC(copyPath, content); err != nil {
-					return fmt.Errorf("failed to write %s: %v", copyPath, err)
+			// Write all aliases
+			for _, alias := range pkgConfigAliases(entry.Name(), info) {
+				aliasPath := filepath.Join(pkgConfigDir, alias)
+				if err := writePC(aliasPath, content); err != nil {
+					return fmt.Errorf("failed to write %s: %v", aliasPath, err)
 				}
 			}
 		}
@@ -361,8 +271,9 @@ func updatePkgConfig(projectPath string) error {
 }
 
 // installPythonEnv downloads and installs Python standalone build
-func installPythonEnv(projectPath string, version, buildDate string, freeThreaded, debug bool, verbose bool) error {
-	fmt.Printf("Installing Python %s in %s\n", version, projectPath)
+// with the parts selected by profile
+func installPythonEnv(projectPath string, spec pythonSpec, profile *profileFilter, manifest *env.Manifest, verbose bool) error {
+	fmt.Printf("Installing Python %s in %s\n", spec.Version, projectPath)
 	pythonRoot := env.GetPythonRoot(projectPath)
 
 	// Remove existing Python directory if it exists
@@ -371,14 +282,17 @@ func installPythonEnv(projectPath string, version, buildDate string, freeThreade
 	}
 
 	// Get Python URL
-	url := getPythonURL(version, buildDate, runtime.GOARCH, runtime.GOOS, freeThreaded, debug)
+	url := getPythonURL(spec)
 	if url == "" {
 		return fmt.Errorf("unsupported platform")
 	}
 
-	if err := downloadAndExtract("Python", version, url, pythonRoot, "python/install", verbose); err != nil {
+	if err := downloadAndExtractFiltered("Python", spec.Version, url, pythonRoot, "python/install", profile.skip, verbose); err != nil {
 		return fmt.Errorf("error downloading and extracting Python: %v", err)
 	}
+	if excluded := profile.Excluded(); len(excluded) > 0 {
+		fmt.Printf("Left out %d parts of Python with the %s profile, run got restore-python to add them\n", len(excluded), profile.profile)
+	}
 
 	// After extraction, update dylib install names on macOS
 	if runtime.GOOS == "darwin" {
@@ -387,9 +301,22 @@ func installPythonEnv(projectPath string, version, buildDate string, freeThreade
 		}
 	}
 
+	info, err := pythonInfo(pythonRoot)
+	if err != nil {
+		return fmt.Errorf("error reading Python build info: %v", err)
+	}
+	manifest.Python = env.NewPythonManifest(info, spec.BuildDate)
+	manifest.Python.Libc = spec.Libc
+	manifest.Python.Microarch = spec.Microarch
+	manifest.Python.Profile = profile.profile
+	manifest.Python.Excluded = profile.Excluded()
+	if info.LinkMode == "static" {
+		warnStaticLibPython(fmt.Sprintf("Python %s for %s", info.Version, info.TargetTriple))
+	}
+
 	if runtime.GOOS == "windows" {
 		pkgConfigDir := env.GetPythonPkgConfigDir(projectPath)
-		if err := genWinPyPkgConfig(pythonRoot, pkgConfigDir); err != nil {
+		if err := genWinPyPkgConfig(info, pkgConfigDir); err != nil {
 			return err
 		}
 	}
@@ -3,6 +3,7 @@ package install
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -16,6 +17,8 @@ func TestGetPythonURL(t *testing.T) {
 		name         string
 		arch         string
 		os           string
+		libc         string
+		microarch    string
 		freeThreaded bool
 
 		debug   bool
@@ -78,6 +81,65 @@ func TestGetPythonURL(t *testing.T) {
 			debug:        false,
 			want:         "cpython-3.13.0+20241016-i686-pc-windows-msvc-shared-freethreaded+pgo-full.tar.zst",
 		},
+		{
+			name: "linux-amd64-gnu-pgo",
+			arch: "amd64",
+			os:   "linux",
+			libc: "gnu",
+			want: "cpython-3.13.0+20241016-x86_64-unknown-linux-gnu-pgo-full.tar.zst",
+		},
+		{
+			name: "linux-amd64-musl-lto",
+			arch: "amd64",
+			os:   "linux",
+			libc: "musl",
+			want: "cpython-3.13.0+20241016-x86_64-unknown-linux-musl-lto-full.tar.zst",
+		},
+		{
+			name:         "linux-arm64-musl-freethreaded-lto",
+			arch:         "arm64",
+			os:           "linux",
+			libc:         "musl",
+			freeThreaded: true,
+			want:         "cpython-3.13.0+20241016-aarch64-unknown-linux-musl-freethreaded+lto-full.tar.zst",
+		},
+		{
+			name:  "linux-amd64-musl-debug",
+			arch:  "amd64",
+			os:    "linux",
+			libc:  "musl",
+			debug: true,
+			want:  "cpython-3.13.0+20241016-x86_64-unknown-linux-musl-debug-full.tar.zst",
+		},
+		{
+			name:      "linux-amd64-v3-pgo",
+			arch:      "amd64",
+			os:        "linux",
+			microarch: "v3",
+			want:      "cpython-3.13.0+20241016-x86_64_v3-unknown-linux-gnu-pgo-full.tar.zst",
+		},
+		{
+			name:      "linux-amd64-v4-musl-lto",
+			arch:      "amd64",
+			os:        "linux",
+			libc:      "musl",
+			microarch: "v4",
+			want:      "cpython-3.13.0+20241016-x86_64_v4-unknown-linux-musl-lto-full.tar.zst",
+		},
+		{
+			name:      "darwin-arm64-microarch",
+			arch:      "arm64",
+			os:        "darwin",
+			microarch: "v3",
+			wantErr:   true,
+		},
+		{
+			name:    "unsupported-libc",
+			arch:    "amd64",
+			os:      "linux",
+			libc:    "uclibc",
+			wantErr: true,
+		},
 		{
 			name:         "unsupported-arch",
 			arch:         "mips",
@@ -100,7 +162,16 @@ func TestGetPythonURL(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := getPythonURL("3.13.0", "20241016", tt.arch, tt.os, tt.freeThreaded, tt.debug)
+			got := getPythonURL(pythonSpec{
+				Version:      "3.13.0",
+				BuildDate:    "20241016",
+				Arch:         tt.arch,
+				OS:           tt.os,
+				Libc:         tt.libc,
+				Microarch:    tt.microarch,
+				FreeThreaded: tt.freeThreaded,
+				Debug:        tt.debug,
+			})
 
 			if tt.wantErr {
 				if got != "" {
@@ -335,3 +406,73 @@ Cflags: -I${includedir}`,
 		}
 	})
 }
+
+func TestUpdatePkgConfigWithPythonInfo(t *testing.T) {
+	tmpDir := t.TempDir()
+	pythonRoot := env.GetPythonRoot(tmpDir)
+	pkgConfigDir := env.GetPythonPkgConfigDir(tmpDir)
+	if err := os.MkdirAll(pkgConfigDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	info := `{"python_version": "3.13.0", "python_major_minor_version": "3.13", "python_abi_tag": "d"}`
+	if err := os.WriteFile(filepath.Join(pythonRoot, env.PythonInfoFile), []byte(info), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"python-3.13d.pc":       "prefix=/install\nLibs: -L${prefix}/lib\n",
+		"python-3.13d-embed.pc": "prefix=/install\nLibs: -L${prefix}/lib -lpython3.13d\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(pkgConfigDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := updatePkgConfig(tmpDir); err != nil {
+		t.Fatalf("updatePkgConfig() error = %v", err)
+	}
+
+	for _, name := range []string{"python3d-embed.pc", "python3-embed.pc", "python-3.13-embed.pc"} {
+		content, err := os.ReadFile(filepath.Join(pkgConfigDir, name))
+		if err != nil {
+			t.Errorf("expected alias %s: %v", name, err)
+			continue
+		}
+		if !strings.Contains(string(content), "-lpython3.13d") {
+			t.Errorf("alias %s = %q, want the embed library", name, content)
+		}
+	}
+}
+
+func TestUseExternalPython(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode")
+	}
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not found in PATH")
+	}
+
+	projectDir := t.TempDir()
+	if err := os.MkdirAll(env.GetDepsDir(projectDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := &env.Manifest{}
+	if err := useExternalPython(projectDir, python, manifest, false); err != nil {
+		t.Fatalf("useExternalPython() error = %v", err)
+	}
+
+	if manifest.Python.Source != env.PythonSourceExternal || manifest.Python.Home == "" || manifest.Python.Executable == "" {
+		t.Errorf("useExternalPython() manifest = %+v, want external installation paths", manifest.Python)
+	}
+	if _, err := os.Stat(filepath.Join(manifest.Python.PkgConfigDir, "python3-embed.pc")); err != nil {
+		t.Errorf("python3-embed.pc not available: %v", err)
+	}
+	envs, err := env.ReadEnvFile(projectDir)
+	if err != nil {
+		t.Fatalf("ReadEnvFile() error = %v", err)
+	}
+	if envs["PYTHONHOME"] != manifest.Python.Home || envs["PYTHONPATH"] == "" {
+		t.Errorf("env.txt = %v, want PYTHONHOME %s and PYTHONPATH", envs, manifest.Python.Home)
+	}
+}
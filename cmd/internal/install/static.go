@@ -0,0 +1,79 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/gotray/got/internal/env"
+)
+
+// archiveBuildLibPath is the directory of the static libraries in
+// python-build-standalone full archives
+const archiveBuildLibPath = "python/build/lib/"
+
+// InstallStaticPython prepares linking the project's libpython statically.
+// It extracts the static libraries of python-build-standalone builds if
+// needed and writes pkg-config files linking them, returning their directory.
+func InstallStaticPython(projectPath string, verbose bool) (string, error) {
+	if runtime.GOOS == "windows" {
+		return "", fmt.Errorf("linking libpython statically is not supported on Windows")
+	}
+	m, err := env.ReadManifest(projectPath)
+	if err != nil {
+		return "", fmt.Errorf("linking libpython statically needs the manifest of the project's Python: %v", err)
+	}
+	layout := env.GetPythonLayout(projectPath)
+	info, err := pythonInfo(layout.Home)
+	if err != nil {
+		return "", fmt.Errorf("error reading Python build info: %v", err)
+	}
+
+	buildLibDir := env.GetPythonBuildLibDir(projectPath)
+	lib := env.StaticLibPython(layout, info, buildLibDir)
+	if lib == "" && (m.Python.Source == "" || m.Python.Source == env.PythonSourceStandalone) {
+		if err := installBuildLibs(projectPath, m, buildLibDir, verbose); err != nil {
+			return "", err
+		}
+		lib = env.StaticLibPython(layout, info, buildLibDir)
+	}
+	if lib == "" {
+		return "", fmt.Errorf("Python %s in %s has no static libpython", info.Version, layout.Home)
+	}
+
+	spec := pcSpecFromInfo(info, filepath.ToSlash(layout.Home))
+	spec.LibDir = filepath.ToSlash(layout.LibDir)
+	spec.IncludeDir = filepath.ToSlash(layout.IncludeDir(info))
+	spec.StaticLib = filepath.ToSlash(lib)
+	spec.LibsPrivate = strings.Join(info.StaticLinkFlags(buildLibDir), " ")
+	spec.Static = true
+	pkgConfigDir := env.GetPythonStaticPkgConfigDir(projectPath)
+	if err := writePythonPkgConfig(pkgConfigDir, spec); err != nil {
+		return "", err
+	}
+	return pkgConfigDir, nil
+}
+
+// installBuildLibs extracts the static libraries of the project's
+// python-build-standalone build into buildLibDir
+func installBuildLibs(projectPath string, m *env.Manifest, buildLibDir string, verbose bool) error {
+	spec, err := targetSpec(projectPath, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+	spec.Microarch = m.Python.Microarch
+	url := getPythonURL(spec)
+	if url == "" {
+		return fmt.Errorf("no Python build for %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+	fmt.Printf("Installing static libraries of Python %s in %s\n", spec.Version, buildLibDir)
+	if err := os.RemoveAll(buildLibDir); err != nil {
+		return fmt.Errorf("error removing existing static libraries: %v", err)
+	}
+	if err := downloadAndExtract("Python", spec.Version, url, buildLibDir, archiveBuildLibPath, verbose); err != nil {
+		return fmt.Errorf("error downloading and extracting Python static libraries: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/gotray/got/internal/env"
+)
+
+// gnuTriples maps GOARCH to the GNU triple prefix of Linux cross compilers
+var gnuTriples = map[string]string{
+	"amd64": "x86_64",
+	"arm64": "aarch64",
+	"386":   "i686",
+}
+
+// ParseTarget parses a cross-compilation target such as "linux/arm64"
+func ParseTarget(target string) (string, string, error) {
+	goos, goarch, ok := strings.Cut(target, "/")
+	if !ok || goos == "" || goarch == "" {
+		return "", "", fmt.Errorf("invalid target %q, expected GOOS/GOARCH such as linux/arm64", target)
+	}
+	if goos != "linux" {
+		return "", "", fmt.Errorf("cross-compiling for %s is not supported, only Linux targets are", goos)
+	}
+	if _, ok := gnuTriples[goarch]; !ok {
+		return "", "", fmt.Errorf("cross-compiling for linux/%s is not supported, supported architectures are amd64, arm64 and 386", goarch)
+	}
+	return goos, goarch, nil
+}
+
+// targetSpec returns the build of the project's Python for goos/goarch
+func targetSpec(projectPath, goos, goarch string) (pythonSpec, error) {
+	m, err := env.ReadManifest(projectPath)
+	if err != nil {
+		return pythonSpec{}, fmt.Errorf("cross-compiling needs the manifest of the project's Python: %v", err)
+	}
+	py := m.Python
+	buildDate := py.BuildDate
+	if buildDate == "" {
+		// External Pythons have no build date, use the newest build of the version
+		buildDate = "latest"
+	}
+	libc := py.Libc
+	if libc == "" {
+		libc = libcGNU
+	}
+	spec := pythonSpec{
+		Arch:         goarch,
+		OS:           goos,
+		Libc:         libc,
+		FreeThreaded: py.FreeThreaded,
+		Debug:        py.Debug,
+	}
+	if spec.Version, spec.BuildDate, err = resolvePythonBuild(py.Version, buildDate, spec); err != nil {
+		return pythonSpec{}, err
+	}
+	return spec, nil
+}
+
+// InstallTarget installs the python-build-standalone build matching the
+// project's Python for goos/goarch into .deps/targets, unless already present
+func InstallTarget(projectPath, goos, goarch string, verbose bool) error {
+	spec, err := targetSpec(projectPath, goos, goarch)
+	if err != nil {
+		return err
+	}
+	releases, err := pythonReleasesFor(spec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: skipping the release index check: %v\n", err)
+	}
+	if err := validatePythonSpec(spec, releases); err != nil {
+		return err
+	}
+
+	url := getPythonURL(spec)
+	if url == "" {
+		return fmt.Errorf("no Python build for %s/%s", goos, goarch)
+	}
+	targetDir := env.GetTargetDir(projectPath, goos, goarch)
+	pythonRoot := env.GetTargetPythonRoot(projectPath, goos, goarch)
+	sourcePath := env.GetTargetSourcePath(projectPath, goos, goarch)
+	// The archive URL identifies the version, build date, libc and variant
+	if source, err := os.ReadFile(sourcePath); err == nil && strings.TrimSpace(string(source)) == url {
+		if verbose {
+			fmt.Printf("Using Python %s for %s/%s in %s\n", spec.Version, goos, goarch, targetDir)
+		}
+		return nil
+	}
+
+	fmt.Printf("Installing Python %s for %s/%s in %s\n", spec.Version, goos, goarch, targetDir)
+	if err := os.RemoveAll(targetDir); err != nil {
+		return fmt.Errorf("error removing existing target directory: %v", err)
+	}
+	if err := downloadAndExtract("Python", spec.Version, url, pythonRoot, "python/install", verbose); err != nil {
+		return fmt.Errorf("error downloading and extracting Python: %v", err)
+	}
+
+	info, err := env.NewPythonEnv(pythonRoot).Info()
+	if err != nil {
+		return fmt.Errorf("error reading Python build info: %v", err)
+	}
+	// The /install prefix is kept and resolved through PKG_CONFIG_SYSROOT_DIR
+	if err := rewritePkgConfig(env.GetTargetPkgConfigDir(projectPath, goos, goarch), "/install", info); err != nil {
+		return fmt.Errorf("error updating pkg-config: %v", err)
+	}
+	if err := os.WriteFile(sourcePath, []byte(url+"\n"), 0644); err != nil {
+		return fmt.Errorf("error recording the target Python: %v", err)
+	}
+	return nil
+}
+
+// TargetCC returns the C and C++ compilers for goos/goarch. An explicitly
+// configured compiler wins, then the project's zig toolchain, then a GNU
+// cross compiler such as aarch64-linux-gnu-gcc found in PATH.
+func TargetCC(projectPath, goos, goarch, cc string) (string, string, error) {
+	if cc != "" {
+		return cc, cxxFor(cc), nil
+	}
+	libc := libcGNU
+	if m, err := env.ReadManifest(projectPath); err == nil && m.Python.Libc != "" {
+		libc = m.Python.Libc
+	}
+
+	zig := filepath.Join(env.GetZigDir(projectPath), "zig")
+	if _, err := os.Stat(zig); err == nil {
+		target := zigTarget(goos, goarch, libc)
+		return zig + " cc -target " + target, zig + " c++ -target " + target, nil
+	}
+
+	triple := fmt.Sprintf("%s-%s-%s", gnuTriples[goarch], goos, libc)
+	gcc := triple + "-gcc"
+	if path, err := exec.LookPath(gcc); err == nil {
+		cxx, _ := exec.LookPath(triple + "-g++")
+		return path, cxx, nil
+	}
+	if goos == runtime.GOOS && goarch == runtime.GOARCH {
+		return "", "", nil
+	}
+	return "", "", fmt.Errorf("no C cross compiler for %s/%s: install %s (e.g. apt install gcc-%s), pass --target-cc, or run `got init . --c-toolchain zig`",
+		goos, goarch, gcc, strings.ReplaceAll(triple, "_", "-"))
+}
+
+// cxxFor returns the C++ compiler matching the C compiler command cc, such
+// as aarch64-linux-gnu-g++ for aarch64-linux-gnu-gcc, or an empty string if
+// it can't be derived
+func cxxFor(cc string) string {
+	fields := strings.Fields(cc)
+	if len(fields) == 0 {
+		return ""
+	}
+	// zig cc -target ...
+	if len(fields) > 1 && fields[1] == "cc" && strings.TrimSuffix(filepath.Base(fields[0]), ".exe") == "zig" {
+		fields[1] = "c++"
+		return strings.Join(fields, " ")
+	}
+	dir, name := filepath.Split(fields[0])
+	ext := filepath.Ext(name)
+	if ext != ".exe" {
+		ext = ""
+	}
+	base := strings.TrimSuffix(name, ext)
+	switch {
+	case strings.HasSuffix(base, "clang"):
+		base += "++"
+	case strings.HasSuffix(base, "gcc"):
+		base = strings.TrimSuffix(base, "gcc") + "g++"
+	case base == "cc" || strings.HasSuffix(base, "-cc"):
+		base = strings.TrimSuffix(base, "cc") + "c++"
+	default:
+		return ""
+	}
+	fields[0] = dir + base + ext
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,117 @@
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gotray/got/internal/env"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		target       string
+		goos, goarch string
+		wantErr      bool
+	}{
+		{target: "linux/arm64", goos: "linux", goarch: "arm64"},
+		{target: "linux/386", goos: "linux", goarch: "386"},
+		{target: "linux", wantErr: true},
+		{target: "darwin/arm64", wantErr: true},
+		{target: "linux/riscv64", wantErr: true},
+	}
+	for _, tt := range tests {
+		goos, goarch, err := ParseTarget(tt.target)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTarget(%q) error = %v, wantErr %v", tt.target, err, tt.wantErr)
+			continue
+		}
+		if goos != tt.goos || goarch != tt.goarch {
+			t.Errorf("ParseTarget(%q) = %s/%s, want %s/%s", tt.target, goos, goarch, tt.goos, tt.goarch)
+		}
+	}
+}
+
+func TestTargetCC(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cross compilers are looked up as Unix executables")
+	}
+
+	projectDir := t.TempDir()
+	if err := os.MkdirAll(env.GetDepsDir(projectDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.WriteManifest(projectDir, &env.Manifest{Python: env.PythonManifest{Version: "3.13.0", Libc: libcMusl}}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("configured compiler", func(t *testing.T) {
+		cc, cxx, err := TargetCC(projectDir, "linux", "arm64", "clang --target=aarch64-linux-gnu")
+		if err != nil || cc != "clang --target=aarch64-linux-gnu" || cxx != "clang++ --target=aarch64-linux-gnu" {
+			t.Errorf("TargetCC() = %q, %q, %v, want the configured compilers", cc, cxx, err)
+		}
+	})
+
+	t.Run("gnu cross compiler", func(t *testing.T) {
+		binDir := t.TempDir()
+		for _, name := range []string{"aarch64-linux-musl-gcc", "aarch64-linux-musl-g++"} {
+			if err := os.WriteFile(filepath.Join(binDir, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		t.Setenv("PATH", binDir)
+		cc, cxx, err := TargetCC(projectDir, "linux", "arm64", "")
+		if err != nil {
+			t.Fatalf("TargetCC() error = %v", err)
+		}
+		if cc != filepath.Join(binDir, "aarch64-linux-musl-gcc") || cxx != filepath.Join(binDir, "aarch64-linux-musl-g++") {
+			t.Errorf("TargetCC() = %q, %q, want the musl cross compilers", cc, cxx)
+		}
+	})
+
+	t.Run("missing cross compiler", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+		_, _, err := TargetCC(projectDir, "linux", "386", "")
+		if err == nil || !strings.Contains(err.Error(), "i686-linux-musl-gcc") {
+			t.Errorf("TargetCC() error = %v, want a missing i686-linux-musl-gcc error", err)
+		}
+	})
+
+	t.Run("zig toolchain", func(t *testing.T) {
+		zig := filepath.Join(env.GetZigDir(projectDir), "zig")
+		if err := os.MkdirAll(filepath.Dir(zig), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(zig, nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+		cc, cxx, err := TargetCC(projectDir, "linux", "arm64", "")
+		if err != nil {
+			t.Fatalf("TargetCC() error = %v", err)
+		}
+		if cc != zig+" cc -target aarch64-linux-musl" || cxx != zig+" c++ -target aarch64-linux-musl" {
+			t.Errorf("TargetCC() = %q, %q, want zig for aarch64-linux-musl", cc, cxx)
+		}
+	})
+}
+
+func TestCxxFor(t *testing.T) {
+	tests := map[string]string{
+		"aarch64-linux-gnu-gcc":             "aarch64-linux-gnu-g++",
+		"/opt/cross/bin/aarch64-linux-gcc":  "/opt/cross/bin/aarch64-linux-g++",
+		"clang --target=aarch64-linux-gnu":  "clang++ --target=aarch64-linux-gnu",
+		"/usr/bin/clang-18":                 "",
+		"zig cc -target aarch64-linux-musl": "zig c++ -target aarch64-linux-musl",
+		"x86_64-w64-mingw32-gcc.exe":        "x86_64-w64-mingw32-g++.exe",
+		"cc":                                "c++",
+		"tcc":                               "",
+		"  ":                                "",
+	}
+	for cc, want := range tests {
+		if got := cxxFor(cc); got != want {
+			t.Errorf("cxxFor(%q) = %q, want %q", cc, got, want)
+		}
+	}
+}
@@ -0,0 +1,464 @@
+package install
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// pythonReleasesURL lists python-build-standalone releases with their assets
+	pythonReleasesURL = "https://api.github.com/repos/indygreg/python-build-standalone/releases?per_page=100"
+	// goReleasesURL lists all published Go releases
+	goReleasesURL = "https://go.dev/dl/?mode=json&include=all"
+	// releaseIndexTTL is how long a cached release index is used without refreshing
+	releaseIndexTTL = 24 * time.Hour
+)
+
+// pythonReleasesCache is the cache file of the python-build-standalone release index
+const pythonReleasesCache = "python-releases.json"
+
+// The Python installed by default, pinned to a build known to publish it
+const (
+	DefaultPythonVersion   = "3.13.0"
+	DefaultPythonBuildDate = "20241016"
+)
+
+// maxIndexPages bounds the pages of a paginated release index
+const maxIndexPages = 50
+
+// pythonAssetPattern matches python-build-standalone archive names, capturing version and build date
+var pythonAssetPattern = regexp.MustCompile(`^cpython-([^+]+)\+(\d{8})-`)
+
+// PythonRelease is a python-build-standalone release identified by its build date
+type PythonRelease struct {
+	BuildDate string   `json:"build_date"`
+	Assets    []string `json:"assets"`
+}
+
+// Versions returns the CPython versions published in the release
+func (r PythonRelease) Versions() []string {
+	seen := map[string]bool{}
+	var versions []string
+	for _, asset := range r.Assets {
+		if m := pythonAssetPattern.FindStringSubmatch(asset); m != nil && !seen[m[1]] {
+			seen[m[1]] = true
+			versions = append(versions, m[1])
+		}
+	}
+	sortVersionsDesc(versions)
+	return versions
+}
+
+// PythonVersion is a CPython version with the build dates it was published in, newest first
+type PythonVersion struct {
+	Version    string
+	BuildDates []string
+}
+
+// GoRelease is a Go release as listed by go.dev/dl
+type GoRelease struct {
+	Version string `json:"version"`
+	Stable  bool   `json:"stable"`
+}
+
+// parseVersion parses a dotted numeric version such as "3.13.0" or "1.23".
+// Pre-release versions like "3.14.0a1" or "1.24rc1" are rejected.
+func parseVersion(v string) ([]int, bool) {
+	parts := strings.Split(v, ".")
+	nums := make([]int, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		nums = append(nums, n)
+	}
+	return nums, len(nums) > 0
+}
+
+// compareVersions compares two dotted versions, treating missing components as zero
+func compareVersions(a, b string) int {
+	va, _ := parseVersion(a)
+	vb, _ := parseVersion(b)
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func sortVersionsDesc(versions []string) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return compareVersions(versions[i], versions[j]) > 0
+	})
+}
+
+// isExactVersion reports whether v names a single release such as "3.13.0"
+func isExactVersion(v string) bool {
+	nums, ok := parseVersion(v)
+	return ok && len(nums) == 3
+}
+
+// matchVersionSpec reports whether a stable version matches spec.
+// Supported specs are "latest" (or empty), "3", "3.12", "3.12.x" and "3.12.1".
+func matchVersionSpec(spec, version string) bool {
+	nums, ok := parseVersion(version)
+	if !ok {
+		return false
+	}
+	spec = strings.TrimSuffix(strings.TrimSuffix(spec, ".x"), ".*")
+	if spec == "" || spec == "latest" {
+		return true
+	}
+	want, ok := parseVersion(spec)
+	if !ok || len(want) > len(nums) {
+		// "1.20" is published as "1.20", so compare with an implied ".0"
+		if ok && len(want) == len(nums)+1 && want[len(want)-1] == 0 {
+			nums = append(nums, 0)
+		} else {
+			return false
+		}
+	}
+	for i, n := range want {
+		if nums[i] != n {
+			return false
+		}
+	}
+	return true
+}
+
+// loadReleaseIndex returns the index stored at url, parsed by parse.
+// The parsed index is cached as cacheName and reused while younger than
+// releaseIndexTTL; when the index cannot be fetched the cached copy is used
+// regardless of its age so that resolution keeps working offline.
+func loadReleaseIndex[T any](url, cacheName string, refresh bool, parse func(io.Reader) (T, error)) (T, error) {
+	var index T
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return index, err
+	}
+	cacheFile := filepath.Join(cacheDir, cacheName)
+
+	if !refresh {
+		if fi, err := os.Stat(cacheFile); err == nil && time.Since(fi.ModTime()) < releaseIndexTTL {
+			if cached, err := readCachedIndex[T](cacheName); err == nil {
+				return cached, nil
+			}
+		}
+	}
+
+	index, fetchErr := fetchReleaseIndex(url, parse)
+	if fetchErr != nil {
+		cached, err := readCachedIndex[T](cacheName)
+		if err != nil {
+			return index, fmt.Errorf("failed to fetch %s and no cached copy is available: %v", url, fetchErr)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: failed to fetch %s, using cached copy: %v\n", url, fetchErr)
+		return cached, nil
+	}
+
+	content, err := json.Marshal(index)
+	if err != nil {
+		return index, fmt.Errorf("failed to encode release index: %v", err)
+	}
+	if err := os.WriteFile(cacheFile, content, 0644); err != nil {
+		return index, fmt.Errorf("failed to write release index cache: %v", err)
+	}
+	return index, nil
+}
+
+// readCachedIndex returns the index cached as cacheName, whatever its age
+func readCachedIndex[T any](cacheName string) (T, error) {
+	var index T
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return index, err
+	}
+	content, err := os.ReadFile(filepath.Join(cacheDir, cacheName))
+	if err != nil {
+		return index, err
+	}
+	err = json.Unmarshal(content, &index)
+	return index, err
+}
+
+// fetchReleaseIndex fetches the index at url, following the pages of a
+// paginated JSON array listing through its Link headers
+func fetchReleaseIndex[T any](url string, parse func(io.Reader) (T, error)) (T, error) {
+	var index T
+	var items []json.RawMessage
+	for page := 0; url != ""; page++ {
+		if page == maxIndexPages {
+			return index, fmt.Errorf("release index has more than %d pages", maxIndexPages)
+		}
+		body, next, err := fetchIndexPage(url)
+		if err != nil {
+			return index, err
+		}
+		if page == 0 && next == "" {
+			return parse(bytes.NewReader(body))
+		}
+		var pageItems []json.RawMessage
+		if err := json.Unmarshal(body, &pageItems); err != nil {
+			return index, fmt.Errorf("failed to parse page %s: %v", url, err)
+		}
+		items = append(items, pageItems...)
+		url = next
+	}
+	content, err := json.Marshal(items)
+	if err != nil {
+		return index, err
+	}
+	return parse(bytes.NewReader(content))
+}
+
+// fetchIndexPage returns the body of url and the URL of its next page
+func fetchIndexPage(url string) ([]byte, string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" && strings.HasPrefix(url, "https://api.github.com/") {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("bad status: %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, nextPageURL(resp.Header.Get("Link")), nil
+}
+
+// nextPageURL returns the rel="next" URL of a Link header
+func nextPageURL(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		target, params, ok := strings.Cut(part, ";")
+		if !ok || !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(target), "<>")
+	}
+	return ""
+}
+
+// parsePythonReleases converts the GitHub releases listing into PythonReleases
+func parsePythonReleases(r io.Reader) ([]PythonRelease, error) {
+	var listing []struct {
+		TagName string `json:"tag_name"`
+		Assets  []struct {
+			Name string `json:"name"`
+		} `json:"assets"`
+	}
+	if err := json.NewDecoder(r).Decode(&listing); err != nil {
+		return nil, fmt.Errorf("failed to parse python-build-standalone releases: %v", err)
+	}
+
+	releases := make([]PythonRelease, 0, len(listing))
+	for _, rel := range listing {
+		release := PythonRelease{BuildDate: rel.TagName}
+		for _, asset := range rel.Assets {
+			if strings.HasSuffix(asset.Name, ".tar.zst") && pythonAssetPattern.MatchString(asset.Name) {
+				release.Assets = append(release.Assets, asset.Name)
+			}
+		}
+		if len(release.Assets) > 0 {
+			releases = append(releases, release)
+		}
+	}
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].BuildDate > releases[j].BuildDate
+	})
+	return releases, nil
+}
+
+// parseGoReleases converts the go.dev/dl JSON listing into GoReleases
+func parseGoReleases(r io.Reader) ([]GoRelease, error) {
+	var releases []GoRelease
+	if err := json.NewDecoder(r).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("failed to parse Go releases: %v", err)
+	}
+	for i := range releases {
+		releases[i].Version = strings.TrimPrefix(releases[i].Version, "go")
+	}
+	return releases, nil
+}
+
+// PythonReleases returns python-build-standalone releases, newest first
+func PythonReleases(refresh bool) ([]PythonRelease, error) {
+	return loadReleaseIndex(pythonReleasesURL, pythonReleasesCache, refresh, parsePythonReleases)
+}
+
+// pythonReleasesFor returns the release index to validate spec against,
+// reading only the cached index when it already lists the build date of spec
+func pythonReleasesFor(spec pythonSpec) ([]PythonRelease, error) {
+	if cached, err := readCachedIndex[[]PythonRelease](pythonReleasesCache); err == nil {
+		for _, rel := range cached {
+			if rel.BuildDate == spec.BuildDate {
+				return cached, nil
+			}
+		}
+	}
+	return PythonReleases(false)
+}
+
+// GoReleases returns Go releases as listed by go.dev/dl
+func GoReleases(refresh bool) ([]GoRelease, error) {
+	return loadReleaseIndex(goReleasesURL, "go-releases.json", refresh, parseGoReleases)
+}
+
+// PythonVersions returns stable CPython versions, newest first, with the build dates providing them
+func PythonVersions(releases []PythonRelease) []PythonVersion {
+	dates := map[string][]string{}
+	var versions []string
+	for _, rel := range releases {
+		for _, v := range rel.Versions() {
+			if !isExactVersion(v) {
+				continue
+			}
+			if _, ok := dates[v]; !ok {
+				versions = append(versions, v)
+			}
+			dates[v] = append(dates[v], rel.BuildDate)
+		}
+	}
+	sortVersionsDesc(versions)
+
+	result := make([]PythonVersion, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, PythonVersion{Version: v, BuildDates: dates[v]})
+	}
+	return result
+}
+
+// GoVersions returns stable Go versions, newest first
+func GoVersions(releases []GoRelease) []string {
+	var versions []string
+	for _, rel := range releases {
+		if rel.Stable {
+			if _, ok := parseVersion(rel.Version); ok {
+				versions = append(versions, rel.Version)
+			}
+		}
+	}
+	sortVersionsDesc(versions)
+	return versions
+}
+
+// ResolvePythonVersion resolves a version spec such as "3.13", "3.12.x" or
+// "latest" and an optional build date ("" or "latest" for the newest) into an
+// exact version and build date. An exact version with an explicit build date
+// is returned as is without consulting the release index.
+func ResolvePythonVersion(spec, buildDate string) (string, string, error) {
+	return resolvePythonBuild(spec, buildDate, pythonSpec{})
+}
+
+// resolvePythonBuild is ResolvePythonVersion considering only the releases
+// publishing an archive for the platform and variant of build, unless its OS
+// is empty. Without a build date the default version uses its pinned build.
+func resolvePythonBuild(spec, buildDate string, build pythonSpec) (string, string, error) {
+	if buildDate == "" && spec == DefaultPythonVersion {
+		buildDate = DefaultPythonBuildDate
+	}
+	if buildDate == "latest" {
+		buildDate = ""
+	}
+	if isExactVersion(spec) && buildDate != "" {
+		return spec, buildDate, nil
+	}
+
+	releases, err := PythonReleases(false)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve Python version %q: %v", spec, err)
+	}
+	return resolvePythonVersion(releases, spec, buildDate, build)
+}
+
+func resolvePythonVersion(releases []PythonRelease, spec, buildDate string, build pythonSpec) (string, string, error) {
+	assets := map[string]bool{}
+	for _, rel := range releases {
+		for _, asset := range rel.Assets {
+			assets[asset] = true
+		}
+	}
+	// published reports whether the archive of version in build date exists
+	published := func(version, date string) bool {
+		if build.OS == "" {
+			return true
+		}
+		s := build
+		s.Version, s.BuildDate = version, date
+		return assets[getPythonFilename(s)]
+	}
+
+	for _, v := range PythonVersions(releases) {
+		if !matchVersionSpec(spec, v.Version) {
+			continue
+		}
+		for _, date := range v.BuildDates {
+			if (buildDate == "" || date == buildDate) && published(v.Version, date) {
+				return v.Version, date, nil
+			}
+		}
+	}
+	platform := ""
+	if build.OS != "" {
+		platform = fmt.Sprintf(" with a %s/%s build", build.OS, build.Arch)
+	}
+	if buildDate != "" {
+		return "", "", fmt.Errorf("no Python release matching %q%s in build %s", spec, platform, buildDate)
+	}
+	return "", "", fmt.Errorf("no Python release matching %q%s", spec, platform)
+}
+
+// ResolveGoVersion resolves a version spec such as "1.23" or "latest" into an
+// exact Go version. Exact versions are returned without consulting the release index.
+func ResolveGoVersion(spec string) (string, error) {
+	spec = strings.TrimPrefix(spec, "go")
+	if isExactVersion(spec) {
+		return spec, nil
+	}
+
+	releases, err := GoReleases(false)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve Go version %q: %v", spec, err)
+	}
+	return resolveGoVersion(releases, spec)
+}
+
+func resolveGoVersion(releases []GoRelease, spec string) (string, error) {
+	for _, v := range GoVersions(releases) {
+		if matchVersionSpec(spec, v) {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("no Go release matching %q", spec)
+}
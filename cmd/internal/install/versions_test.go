@@ -0,0 +1,316 @@
+package install
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+const pythonReleasesFixture = `[
+  {
+    "tag_name": "20241008",
+    "assets": [
+      {"name": "cpython-3.13.0+20241008-x86_64-unknown-linux-gnu-pgo-full.tar.zst"},
+      {"name": "cpython-3.12.7+20241008-x86_64-unknown-linux-gnu-pgo-full.tar.zst"},
+      {"name": "cpython-3.12.7+20241008-x86_64-unknown-linux-gnu-pgo-full.tar.zst.sha256"}
+    ]
+  },
+  {
+    "tag_name": "20241016",
+    "assets": [
+      {"name": "cpython-3.14.0a1+20241016-x86_64-unknown-linux-gnu-pgo-full.tar.zst"},
+      {"name": "cpython-3.13.0+20241016-x86_64-unknown-linux-gnu-pgo-full.tar.zst"},
+      {"name": "cpython-3.12.7+20241016-x86_64-unknown-linux-gnu-pgo-full.tar.zst"},
+      {"name": "cpython-3.9.20+20241016-x86_64-unknown-linux-gnu-pgo-full.tar.zst"}
+    ]
+  },
+  {
+    "tag_name": "20240909",
+    "assets": [
+      {"name": "cpython-3.12.6+20240909-x86_64-unknown-linux-gnu-pgo-full.tar.zst"}
+    ]
+  }
+]`
+
+const goReleasesFixture = `[
+  {"version": "go1.24rc1", "stable": false},
+  {"version": "go1.23.3", "stable": true},
+  {"version": "go1.23.2", "stable": true},
+  {"version": "go1.22.9", "stable": true},
+  {"version": "go1.20", "stable": true},
+  {"version": "go1.20.1", "stable": true}
+]`
+
+// setupReleaseServer serves the release fixtures and points the index URLs and cache at test locations
+func setupReleaseServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	home := t.TempDir()
+	if runtime.GOOS == "windows" {
+		t.Setenv("USERPROFILE", home)
+	} else {
+		t.Setenv("HOME", home)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/python", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(pythonReleasesFixture))
+	})
+	mux.HandleFunc("/go", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(goReleasesFixture))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	origPython, origGo := pythonReleasesURL, goReleasesURL
+	pythonReleasesURL = server.URL + "/python"
+	goReleasesURL = server.URL + "/go"
+	t.Cleanup(func() {
+		pythonReleasesURL, goReleasesURL = origPython, origGo
+	})
+	return server
+}
+
+func TestResolvePythonVersion(t *testing.T) {
+	setupReleaseServer(t)
+
+	tests := []struct {
+		spec, buildDate       string
+		wantVersion, wantDate string
+		wantErr               bool
+	}{
+		{spec: "3.13.0", buildDate: "20200101", wantVersion: "3.13.0", wantDate: "20200101"},
+		{spec: "latest", buildDate: "", wantVersion: "3.13.0", wantDate: "20241016"},
+		{spec: "latest", buildDate: "latest", wantVersion: "3.13.0", wantDate: "20241016"},
+		{spec: "3.12", buildDate: "", wantVersion: "3.12.7", wantDate: "20241016"},
+		{spec: "3.12.x", buildDate: "20241008", wantVersion: "3.12.7", wantDate: "20241008"},
+		{spec: "3.12", buildDate: "20240909", wantVersion: "3.12.6", wantDate: "20240909"},
+		{spec: "3.13.0", buildDate: "", wantVersion: "3.13.0", wantDate: "20241016"},
+		{spec: "3", buildDate: "", wantVersion: "3.13.0", wantDate: "20241016"},
+		{spec: "3.11", buildDate: "", wantErr: true},
+		{spec: "3.13", buildDate: "20240909", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec+"@"+tt.buildDate, func(t *testing.T) {
+			version, date, err := ResolvePythonVersion(tt.spec, tt.buildDate)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ResolvePythonVersion() = %s+%s, want error", version, date)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolvePythonVersion() error = %v", err)
+			}
+			if version != tt.wantVersion || date != tt.wantDate {
+				t.Errorf("ResolvePythonVersion() = %s+%s, want %s+%s", version, date, tt.wantVersion, tt.wantDate)
+			}
+		})
+	}
+}
+
+func TestResolveGoVersion(t *testing.T) {
+	setupReleaseServer(t)
+
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "1.21.5", want: "1.21.5"},
+		{spec: "go1.21.5", want: "1.21.5"},
+		{spec: "latest", want: "1.23.3"},
+		{spec: "1.23", want: "1.23.3"},
+		{spec: "1.22.x", want: "1.22.9"},
+		{spec: "1.20", want: "1.20.1"},
+		{spec: "1.19", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ResolveGoVersion(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ResolveGoVersion() = %s, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveGoVersion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveGoVersion() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReleaseIndexCache(t *testing.T) {
+	server := setupReleaseServer(t)
+
+	if _, err := PythonReleases(false); err != nil {
+		t.Fatalf("PythonReleases() error = %v", err)
+	}
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "python-releases.json")); err != nil {
+		t.Fatalf("release index was not cached: %v", err)
+	}
+
+	// Offline: a forced refresh falls back to the cached copy
+	server.Close()
+	releases, err := PythonReleases(true)
+	if err != nil {
+		t.Fatalf("PythonReleases() offline error = %v", err)
+	}
+	versions := PythonVersions(releases)
+	if len(versions) == 0 || versions[0].Version != "3.13.0" {
+		t.Errorf("PythonVersions() from cache = %v, want 3.13.0 first", versions)
+	}
+	for _, v := range versions {
+		if v.Version == "3.14.0a1" {
+			t.Errorf("PythonVersions() includes pre-release %s", v.Version)
+		}
+	}
+
+	// Without any cached copy resolution fails
+	if _, err := GoReleases(true); err == nil {
+		t.Error("GoReleases() error = nil, want error without network or cache")
+	}
+}
+
+func TestResolvePythonVersionPlatform(t *testing.T) {
+	arm := pythonSpec{OS: "linux", Arch: "arm64", Libc: libcGNU}
+	armFT := arm
+	armFT.FreeThreaded = true
+
+	with := func(spec pythonSpec, version, date string) pythonSpec {
+		spec.Version, spec.BuildDate = version, date
+		return spec
+	}
+	releases := []PythonRelease{
+		// The newest release has no free-threaded arm64 build
+		{BuildDate: "20241016", Assets: []string{getPythonFilename(with(arm, "3.13.0", "20241016"))}},
+		{BuildDate: "20241008", Assets: []string{getPythonFilename(with(arm, "3.13.0", "20241008")), getPythonFilename(with(armFT, "3.13.0", "20241008"))}},
+	}
+
+	tests := []struct {
+		build    pythonSpec
+		wantDate string
+		wantErr  bool
+	}{
+		{build: arm, wantDate: "20241016"},
+		{build: armFT, wantDate: "20241008"},
+		{build: pythonSpec{OS: "linux", Arch: "386", Libc: libcGNU}, wantErr: true},
+		{build: pythonSpec{}, wantDate: "20241016"},
+	}
+	for _, tt := range tests {
+		version, date, err := resolvePythonVersion(releases, "latest", "", tt.build)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("resolvePythonVersion(%+v) = %s+%s, want error", tt.build, version, date)
+			}
+			continue
+		}
+		if err != nil || version != "3.13.0" || date != tt.wantDate {
+			t.Errorf("resolvePythonVersion(%+v) = %s+%s, %v, want 3.13.0+%s", tt.build, version, date, err, tt.wantDate)
+		}
+	}
+}
+
+func TestFetchReleaseIndexPages(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", `<`+server.URL+`/?page=2>; rel="next", <`+server.URL+`/?page=2>; rel="last"`)
+			w.Write([]byte(`[{"tag_name": "20241016", "assets": [{"name": "cpython-3.13.0+20241016-x86_64-unknown-linux-gnu-pgo-full.tar.zst"}]}]`))
+		case "2":
+			w.Header().Set("Link", `<`+server.URL+`/>; rel="first"`)
+			w.Write([]byte(`[{"tag_name": "20200101", "assets": [{"name": "cpython-3.8.1+20200101-x86_64-unknown-linux-gnu-pgo-full.tar.zst"}]}]`))
+		}
+	}))
+	defer server.Close()
+
+	releases, err := fetchReleaseIndex(server.URL+"/", parsePythonReleases)
+	if err != nil {
+		t.Fatalf("fetchReleaseIndex() error = %v", err)
+	}
+	if len(releases) != 2 || releases[1].BuildDate != "20200101" {
+		t.Errorf("fetchReleaseIndex() = %+v, want the releases of both pages", releases)
+	}
+}
+
+func TestPythonReleasesFor(t *testing.T) {
+	server := setupReleaseServer(t)
+	fetches := 0
+	handler := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		handler.ServeHTTP(w, r)
+	})
+
+	// An old cached index listing the build date is used without fetching
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheFile := filepath.Join(cacheDir, pythonReleasesCache)
+	if err := os.WriteFile(cacheFile, []byte(`[{"build_date": "20200101", "assets": []}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * releaseIndexTTL)
+	if err := os.Chtimes(cacheFile, old, old); err != nil {
+		t.Fatal(err)
+	}
+	releases, err := pythonReleasesFor(pythonSpec{Version: "3.8.1", BuildDate: "20200101"})
+	if err != nil || len(releases) != 1 || fetches != 0 {
+		t.Errorf("pythonReleasesFor(cached build) = %d releases, %v, %d fetches, want the cache without fetching", len(releases), err, fetches)
+	}
+
+	// A build date the cache doesn't know refreshes the index
+	releases, err = pythonReleasesFor(pythonSpec{Version: "3.13.0", BuildDate: "20241016"})
+	if err != nil || len(releases) != 3 || fetches != 1 {
+		t.Errorf("pythonReleasesFor(new build) = %d releases, %v, %d fetches, want the fetched index", len(releases), err, fetches)
+	}
+}
+
+func TestResolvePythonBuildDefaultDate(t *testing.T) {
+	setupReleaseServer(t)
+	newer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+  {"tag_name": "20250212", "assets": [
+    {"name": "cpython-3.14.0+20250212-x86_64-unknown-linux-gnu-pgo-full.tar.zst"},
+    {"name": "cpython-3.13.2+20250212-x86_64-unknown-linux-gnu-pgo-full.tar.zst"}
+  ]},
+  {"tag_name": "20241016", "assets": [
+    {"name": "cpython-3.13.0+20241016-x86_64-unknown-linux-gnu-pgo-full.tar.zst"}
+  ]}
+]`))
+	}))
+	defer newer.Close()
+	pythonReleasesURL = newer.URL
+
+	// Without --python-build-date only the default version keeps its pinned build
+	tests := []struct {
+		spec                  string
+		wantVersion, wantDate string
+	}{
+		{DefaultPythonVersion, DefaultPythonVersion, DefaultPythonBuildDate},
+		{"3.x", "3.14.0", "20250212"},
+		{"3.13", "3.13.2", "20250212"},
+		{"latest", "3.14.0", "20250212"},
+	}
+	for _, tt := range tests {
+		version, date, err := resolvePythonBuild(tt.spec, "", pythonSpec{})
+		if err != nil || version != tt.wantVersion || date != tt.wantDate {
+			t.Errorf("resolvePythonBuild(%q) = %s+%s, %v, want %s+%s", tt.spec, version, date, err, tt.wantVersion, tt.wantDate)
+		}
+	}
+}
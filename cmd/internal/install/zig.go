@@ -0,0 +1,131 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/gotray/got/internal/env"
+)
+
+const (
+	// zigVersion is the default zig release providing the C toolchain
+	zigVersion = "0.13.0"
+	// zig download URL format: version, os, arch
+	zigDownloadURL = "https://ziglang.org/download/%[1]s/zig-%[2]s-%[3]s-%[1]s.tar.xz"
+)
+
+const (
+	// CToolchainHost builds with the C compiler installed on the host
+	CToolchainHost = "host"
+	// CToolchainZig builds with zig cc installed in .deps/zig
+	CToolchainZig = "zig"
+)
+
+var zigArchMap = map[string]string{
+	"amd64": "x86_64",
+	"arm64": "aarch64",
+	"386":   "x86",
+}
+
+// zigPlatform returns the OS and architecture names zig releases use
+func zigPlatform(goos, goarch string) (string, string, bool) {
+	arch, ok := zigArchMap[goarch]
+	switch {
+	case !ok:
+		return "", "", false
+	case goos == "linux":
+		return "linux", arch, true
+	case goos == "darwin":
+		return "macos", arch, true
+	default:
+		return "", "", false
+	}
+}
+
+// zigArchiveName returns the top directory of the zig archive, which is also
+// its file name without extension
+func zigArchiveName(version, goos, goarch string) string {
+	zigOS, zigArch, ok := zigPlatform(goos, goarch)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("zig-%s-%s-%s", zigOS, zigArch, version)
+}
+
+// getZigURL returns the zig download URL for the platform
+func getZigURL(version, goos, goarch string) string {
+	zigOS, zigArch, ok := zigPlatform(goos, goarch)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(zigDownloadURL, version, zigOS, zigArch)
+}
+
+// zigTarget returns the zig target triple for Linux, pinning the C library
+// so builds don't pick up the host's glibc version. macOS uses the native target.
+func zigTarget(goos, goarch, libc string) string {
+	if goos != "linux" {
+		return ""
+	}
+	if libc == "" {
+		libc = libcGNU
+	}
+	return fmt.Sprintf("%s-linux-%s", zigArchMap[goarch], libc)
+}
+
+// zigWrapper returns a shell script running zig as the C or C++ compiler
+func zigWrapper(tool, target string) string {
+	args := tool
+	if target != "" {
+		args += " -target " + target
+	}
+	return fmt.Sprintf(`#!/bin/sh
+# Generated by got: use zig %[1]s as the compiler for cgo
+exec "$(dirname "$0")/../zig" %[2]s "$@"
+`, tool, args)
+}
+
+// installZig installs zig into .deps/zig with cc and c++ wrappers used as
+// CC and CXX by the build environment
+func installZig(projectPath, version, libc string, verbose bool) (*env.ZigManifest, error) {
+	if runtime.GOOS == "windows" {
+		return nil, fmt.Errorf("the zig C toolchain is not supported on Windows, which uses MinGW")
+	}
+	if version == "" {
+		version = zigVersion
+	}
+	url := getZigURL(version, runtime.GOOS, runtime.GOARCH)
+	if url == "" {
+		return nil, fmt.Errorf("zig is not available for %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+
+	zigDir := env.GetZigDir(projectPath)
+	fmt.Printf("Installing zig %s in %s\n", version, zigDir)
+	if err := os.RemoveAll(zigDir); err != nil {
+		return nil, fmt.Errorf("error removing existing zig: %v", err)
+	}
+	trimPrefix := zigArchiveName(version, runtime.GOOS, runtime.GOARCH)
+	if err := downloadAndExtract("zig", version, url, zigDir, trimPrefix, verbose); err != nil {
+		return nil, err
+	}
+
+	target := zigTarget(runtime.GOOS, runtime.GOARCH, libc)
+	cc, cxx := env.GetZigCC(projectPath)
+	if err := os.MkdirAll(filepath.Dir(cc), 0755); err != nil {
+		return nil, fmt.Errorf("error creating directory %s: %v", filepath.Dir(cc), err)
+	}
+	for path, tool := range map[string]string{cc: "cc", cxx: "c++"} {
+		if err := os.WriteFile(path, []byte(zigWrapper(tool, target)), 0755); err != nil {
+			return nil, fmt.Errorf("error writing %s: %v", path, err)
+		}
+	}
+
+	if out, err := exec.Command(cc, "--version").CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("zig cc does not run: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return &env.ZigManifest{Version: version, Target: target}, nil
+}
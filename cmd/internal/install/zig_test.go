@@ -0,0 +1,90 @@
+package install
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ulikunitz/xz"
+)
+
+func TestGetZigURL(t *testing.T) {
+	tests := []struct {
+		goos, goarch string
+		want         string
+	}{
+		{"linux", "amd64", "https://ziglang.org/download/0.13.0/zig-linux-x86_64-0.13.0.tar.xz"},
+		{"linux", "arm64", "https://ziglang.org/download/0.13.0/zig-linux-aarch64-0.13.0.tar.xz"},
+		{"darwin", "arm64", "https://ziglang.org/download/0.13.0/zig-macos-aarch64-0.13.0.tar.xz"},
+		{"windows", "amd64", ""},
+		{"linux", "riscv64", ""},
+	}
+	for _, tt := range tests {
+		if got := getZigURL("0.13.0", tt.goos, tt.goarch); got != tt.want {
+			t.Errorf("getZigURL(%s/%s) = %q, want %q", tt.goos, tt.goarch, got, tt.want)
+		}
+	}
+}
+
+func TestZigWrapper(t *testing.T) {
+	if got := zigTarget("linux", "amd64", ""); got != "x86_64-linux-gnu" {
+		t.Errorf("zigTarget(linux/amd64) = %q, want x86_64-linux-gnu", got)
+	}
+	if got := zigTarget("linux", "arm64", libcMusl); got != "aarch64-linux-musl" {
+		t.Errorf("zigTarget(linux/arm64, musl) = %q, want aarch64-linux-musl", got)
+	}
+	if got := zigTarget("darwin", "arm64", ""); got != "" {
+		t.Errorf("zigTarget(darwin/arm64) = %q, want native target", got)
+	}
+
+	script := zigWrapper("c++", "x86_64-linux-gnu")
+	if !strings.HasPrefix(script, "#!/bin/sh\n") || !strings.Contains(script, `/../zig" c++ -target x86_64-linux-gnu "$@"`) {
+		t.Errorf("zigWrapper() =\n%s", script)
+	}
+}
+
+func TestExtractTarXz(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "zig-linux-x86_64-0.13.0.tar.xz")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xw, err := xz.NewWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(xw)
+	files := map[string]string{
+		"zig-linux-x86_64-0.13.0/zig":              "binary",
+		"zig-linux-x86_64-0.13.0/lib/std/std.zig":  "std",
+		"zig-linux-x86_64-0.13.0/doc/langref.html": "doc",
+	}
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, c := range []interface{ Close() error }{tw, xw, f} {
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst := filepath.Join(dir, "zig")
+	if err := extractTarXz(archive, dst, "zig-linux-x86_64-0.13.0"); err != nil {
+		t.Fatalf("extractTarXz() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dst, "lib", "std", "std.zig"))
+	if err != nil || string(content) != "std" {
+		t.Errorf("extracted lib/std/std.zig = %q, %v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "zig")); err != nil {
+		t.Errorf("zig binary not extracted: %v", err)
+	}
+}
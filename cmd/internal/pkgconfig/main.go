@@ -0,0 +1,161 @@
+package pkgconfig
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Version is reported by --version; cgo and build scripts only check that it runs
+const Version = "0.29.2"
+
+const usage = `Usage: got pkg-config [OPTION...] [--] PACKAGE...
+
+Options:
+  --cflags               print the compiler flags of the packages
+  --libs                 print the linker flags of the packages
+  --static               include private libraries and requirements
+  --modversion           print the version of the packages
+  --variable=NAME        print the value of a variable of the packages
+  --exists               exit with status 0 if the packages exist
+  --atleast-version=VER  exit with status 0 if the packages are at least VER
+  --version              print the pkg-config version
+  --print-errors         explain why --exists or --atleast-version failed
+  --silence-errors       don't print errors
+`
+
+// Main runs pkg-config with the command line args, writing results to stdout
+// and errors to stderr, and returns the exit status
+func Main(args []string, stdout, stderr io.Writer) int {
+	var (
+		cflags, libs, static, modversion, exists bool
+		printErrors, silence                     bool
+		variable, atLeast                        string
+		pkgArgs                                  []string
+	)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			pkgArgs = append(pkgArgs, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || isOperator(arg) {
+			pkgArgs = append(pkgArgs, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		switch name {
+		case "cflags":
+			cflags = true
+		case "libs":
+			libs = true
+		case "static":
+			static = true
+		case "modversion":
+			modversion = true
+		case "exists":
+			exists = true
+		case "print-errors":
+			printErrors = true
+		case "short-errors", "errors-to-stdout":
+		case "silence-errors":
+			silence = true
+		case "variable", "atleast-version":
+			if !hasValue {
+				if i+1 >= len(args) {
+					fmt.Fprintf(stderr, "--%s requires a value\n", name)
+					return 1
+				}
+				i++
+				value = args[i]
+			}
+			if name == "variable" {
+				variable = value
+			} else {
+				atLeast = value
+			}
+		case "version":
+			fmt.Fprintln(stdout, Version)
+			return 0
+		case "help", "h":
+			fmt.Fprint(stdout, usage)
+			return 0
+		default:
+			fmt.Fprintf(stderr, "Unknown option %s\n", arg)
+			return 1
+		}
+	}
+
+	// Like pkg-config, queries only explain failures when asked to
+	if (exists || atLeast != "") && !printErrors {
+		silence = true
+	}
+	fail := func(err error) int {
+		if !silence {
+			fmt.Fprintln(stderr, err)
+		}
+		return 1
+	}
+
+	reqs, err := ParseRequirements(strings.Join(pkgArgs, " "))
+	if err != nil {
+		return fail(err)
+	}
+	if len(reqs) == 0 {
+		fmt.Fprintln(stderr, "Must specify package names on the command line")
+		return 1
+	}
+	if atLeast != "" {
+		for i := range reqs {
+			reqs[i].Operator, reqs[i].Version = ">=", atLeast
+		}
+	}
+
+	r := NewResolverFromEnv()
+	pkgs, err := r.Resolve(reqs, static)
+	if err != nil {
+		return fail(err)
+	}
+	if exists || atLeast != "" {
+		return 0
+	}
+
+	// Printed values only concern the packages named on the command line
+	var named []*Package
+	for _, req := range reqs {
+		pkg, _ := r.Find(req.Name)
+		named = append(named, pkg)
+	}
+	switch {
+	case modversion:
+		for _, pkg := range named {
+			fmt.Fprintln(stdout, pkg.Version())
+		}
+		return 0
+	case variable != "":
+		for _, pkg := range named {
+			fmt.Fprintln(stdout, pkg.Variable(variable))
+		}
+		return 0
+	}
+
+	var flags []string
+	if cflags {
+		f, err := r.Cflags(pkgs)
+		if err != nil {
+			return fail(err)
+		}
+		flags = append(flags, f...)
+	}
+	if libs {
+		f, err := r.Libs(pkgs, static)
+		if err != nil {
+			return fail(err)
+		}
+		flags = append(flags, f...)
+	}
+	if cflags || libs {
+		fmt.Fprintln(stdout, Quote(flags))
+	}
+	return 0
+}
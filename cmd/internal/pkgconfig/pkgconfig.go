@@ -0,0 +1,137 @@
+// Package pkgconfig implements the subset of pkg-config used by cgo: it reads
+// .pc files and resolves the compiler and linker flags of packages.
+package pkgconfig
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Package is a parsed .pc file
+type Package struct {
+	Name string // name the package was looked up by, the file name without .pc
+	Path string // path of the .pc file
+
+	vars   map[string]string
+	fields map[string]string
+}
+
+// Parse reads the .pc file at path
+func Parse(path string) (*Package, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	pkg := &Package{
+		Name: strings.TrimSuffix(filepath.Base(path), ".pc"),
+		Path: path,
+		vars: map[string]string{
+			// cgo splits flags with shell rules, so backslashes must not appear
+			"pcfiledir": filepath.ToSlash(filepath.Dir(path)),
+		},
+		fields: map[string]string{},
+	}
+
+	scanner := bufio.NewScanner(f)
+	var line string
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		text := scanner.Text()
+		// A trailing backslash continues the line
+		if strings.HasSuffix(text, "\\") {
+			line += strings.TrimSuffix(text, "\\")
+			continue
+		}
+		line += text
+		if err := pkg.parseLine(line); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, lineNo, err)
+		}
+		line = ""
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if line != "" {
+		if err := pkg.parseLine(line); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, lineNo, err)
+		}
+	}
+	return pkg, nil
+}
+
+func (p *Package) parseLine(line string) error {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		line = line[:i]
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil
+	}
+	// Whichever separator comes first decides between a variable and a field
+	eq := strings.IndexByte(line, '=')
+	colon := strings.IndexByte(line, ':')
+	switch {
+	case eq > 0 && (colon < 0 || eq < colon):
+		name := strings.TrimSpace(line[:eq])
+		value, err := p.expand(strings.TrimSpace(line[eq+1:]))
+		if err != nil {
+			return err
+		}
+		p.vars[name] = value
+	case colon > 0:
+		name := strings.TrimSpace(line[:colon])
+		value, err := p.expand(strings.TrimSpace(line[colon+1:]))
+		if err != nil {
+			return err
+		}
+		p.fields[strings.ToLower(name)] = value
+	default:
+		return fmt.Errorf("invalid line %q", line)
+	}
+	return nil
+}
+
+// expand replaces ${name} references with the variables defined so far
+func (p *Package) expand(s string) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable reference in %q", s)
+		}
+		name := s[start+2 : start+end]
+		value, ok := p.vars[name]
+		if !ok {
+			return "", fmt.Errorf("undefined variable %q", name)
+		}
+		b.WriteString(s[:start])
+		b.WriteString(value)
+		s = s[start+end+1:]
+	}
+}
+
+// Variable returns the value of a variable defined in the .pc file
+func (p *Package) Variable(name string) string {
+	return p.vars[name]
+}
+
+// Field returns the value of a field such as "Cflags" or "Libs.private"
+func (p *Package) Field(name string) string {
+	return p.fields[strings.ToLower(name)]
+}
+
+// Version returns the Version field
+func (p *Package) Version() string {
+	return p.Field("Version")
+}
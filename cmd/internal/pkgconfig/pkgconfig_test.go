@@ -0,0 +1,169 @@
+package pkgconfig
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writePC writes .pc files into a new directory and returns it
+func writePC(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name+".pc"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+var testPackages = map[string]string{
+	"python3-embed": `# See: man pkg-config
+prefix=${pcfiledir}/../..
+exec_prefix=${prefix}
+libdir=${exec_prefix}/lib
+includedir=${prefix}/include
+
+Name: Python
+Description: Embed Python into an application
+Requires: zlib >= 1.2, \
+  ffi
+Version: 3.13
+Libs.private: -ldl -lm
+Libs: -L${libdir} -lpython3.13
+Cflags: -I${includedir}/python3.13
+`,
+	"zlib": `prefix=/opt/zlib
+Name: zlib
+Version: 1.3.1
+Libs: -L${prefix}/lib -lz
+Cflags: -I${prefix}/include
+`,
+	"ffi": `prefix="/opt/my ffi"
+Name: libffi
+Version: 3.4.6
+Requires.private: zlib
+Libs: -L/usr/lib -lffi
+Cflags: -I/usr/include -DFFI_NAME=my\ ffi
+`,
+}
+
+func TestParse(t *testing.T) {
+	dir := writePC(t, testPackages)
+	pkg, err := Parse(filepath.Join(dir, "python3-embed.pc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.ToSlash(dir) + "/../.."
+	if got := pkg.Variable("libdir"); got != root+"/lib" {
+		t.Errorf("libdir = %q, want %q", got, root+"/lib")
+	}
+	if got := pkg.Field("requires"); got != "zlib >= 1.2,   ffi" {
+		t.Errorf("Requires = %q, want the continued line", got)
+	}
+	if pkg.Version() != "3.13" {
+		t.Errorf("Version() = %q, want 3.13", pkg.Version())
+	}
+
+	bad := writePC(t, map[string]string{"bad": "Libs: -L${missing}\n"})
+	if _, err := Parse(filepath.Join(bad, "bad.pc")); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Parse() error = %v, want undefined variable", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := writePC(t, testPackages)
+	root := filepath.ToSlash(dir) + "/../.."
+	r := &Resolver{Path: []string{dir}}
+
+	pkgs, err := r.Resolve([]Requirement{{Name: "python3-embed"}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cflags, _ := r.Cflags(pkgs)
+	wantCflags := []string{"-I" + root + "/include/python3.13", "-DFFI_NAME=my ffi", "-I/opt/zlib/include"}
+	if !reflect.DeepEqual(cflags, wantCflags) {
+		t.Errorf("Cflags() = %q, want %q", cflags, wantCflags)
+	}
+	libs, _ := r.Libs(pkgs, false)
+	wantLibs := []string{"-L" + root + "/lib", "-lpython3.13", "-lffi", "-L/opt/zlib/lib", "-lz"}
+	if !reflect.DeepEqual(libs, wantLibs) {
+		t.Errorf("Libs() = %q, want %q", libs, wantLibs)
+	}
+
+	pkgs, err = r.Resolve([]Requirement{{Name: "python3-embed"}}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	libs, _ = r.Libs(pkgs, true)
+	wantLibs = []string{"-L" + root + "/lib", "-lpython3.13", "-ldl", "-lm", "-lffi", "-L/opt/zlib/lib", "-lz"}
+	if !reflect.DeepEqual(libs, wantLibs) {
+		t.Errorf("static Libs() = %q, want %q", libs, wantLibs)
+	}
+
+	r.SysrootDir = "/sysroot"
+	cflags, _ = r.Cflags(pkgs[2:])
+	if !reflect.DeepEqual(cflags, []string{"-I/sysroot/opt/zlib/include"}) {
+		t.Errorf("Cflags() with sysroot = %q", cflags)
+	}
+
+	if _, err := r.Resolve([]Requirement{{Name: "zlib", Operator: ">=", Version: "1.4"}}, false); err == nil {
+		t.Error("Resolve(zlib >= 1.4) error = nil, want version mismatch")
+	}
+	if _, err := r.Resolve([]Requirement{{Name: "missing"}}, false); err == nil || !strings.Contains(err.Error(), "Package missing was not found") {
+		t.Errorf("Resolve(missing) error = %v, want not found", err)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"3.13", "3.13", 0},
+		{"3.13", "3.9", 1},
+		{"1.2.11", "1.2.3", 1},
+		{"1.0", "1.0.1", -1},
+		{"2.0rc1", "2.0", 1},
+		{"1.0a", "1.0b", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMain(t *testing.T) {
+	dir := writePC(t, testPackages)
+	t.Setenv("PKG_CONFIG_PATH", dir)
+	t.Setenv("PKG_CONFIG_LIBDIR", "")
+	t.Setenv("PKG_CONFIG_SYSROOT_DIR", "")
+
+	tests := []struct {
+		args     []string
+		want     string
+		wantCode int
+	}{
+		{[]string{"--modversion", "zlib"}, "1.3.1\n", 0},
+		{[]string{"--cflags", "--", "ffi"}, `-DFFI_NAME=my\ ffi` + "\n", 0},
+		{[]string{"--libs", "--static", "zlib"}, "-L/opt/zlib/lib -lz\n", 0},
+		{[]string{"--variable=prefix", "zlib"}, "/opt/zlib\n", 0},
+		{[]string{"--exists", "zlib", ">=", "2"}, "", 1},
+		{[]string{"--atleast-version", "1.3", "zlib"}, "", 0},
+		{[]string{"--version"}, Version + "\n", 0},
+		{[]string{"--cflags", "missing"}, "", 1},
+		{[]string{"--bogus", "zlib"}, "", 1},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := Main(tt.args, &stdout, &stderr)
+		if code != tt.wantCode || stdout.String() != tt.want {
+			t.Errorf("Main(%q) = %d, %q (stderr %q), want %d, %q", tt.args, code, stdout.String(), stderr.String(), tt.wantCode, tt.want)
+		}
+	}
+}
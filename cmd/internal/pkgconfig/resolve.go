@@ -0,0 +1,479 @@
+package pkgconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Resolver looks up packages and collects their flags
+type Resolver struct {
+	// Path lists the directories searched for .pc files, in order
+	Path []string
+	// SysrootDir is prepended to the -I and -L paths of packages
+	SysrootDir string
+	// AllowSystemCflags and AllowSystemLibs keep -I/usr/include and -L/usr/lib
+	AllowSystemCflags bool
+	AllowSystemLibs   bool
+
+	cache map[string]*Package
+}
+
+// defaultPath returns the directories searched when PKG_CONFIG_LIBDIR is unset
+func defaultPath() []string {
+	switch runtime.GOOS {
+	case "windows":
+		return nil
+	case "darwin":
+		return []string{
+			"/opt/homebrew/lib/pkgconfig", "/opt/homebrew/share/pkgconfig",
+			"/usr/local/lib/pkgconfig", "/usr/local/share/pkgconfig",
+			"/usr/lib/pkgconfig",
+		}
+	default:
+		var dirs []string
+		for _, prefix := range []string{"/usr/local", "/usr"} {
+			for _, triple := range multiarchTriples() {
+				dirs = append(dirs, prefix+"/lib/"+triple+"/pkgconfig")
+			}
+			dirs = append(dirs, prefix+"/lib64/pkgconfig", prefix+"/lib/pkgconfig", prefix+"/share/pkgconfig")
+		}
+		return dirs
+	}
+}
+
+// multiarchTriples returns the Debian multiarch directory names for the host
+func multiarchTriples() []string {
+	switch runtime.GOARCH {
+	case "amd64":
+		return []string{"x86_64-linux-gnu"}
+	case "arm64":
+		return []string{"aarch64-linux-gnu"}
+	case "386":
+		return []string{"i386-linux-gnu"}
+	default:
+		return nil
+	}
+}
+
+// NewResolverFromEnv configures a resolver from the PKG_CONFIG_* environment
+// variables like pkg-config does
+func NewResolverFromEnv() *Resolver {
+	return NewResolverFromEnviron(os.Environ())
+}
+
+// NewResolverFromEnviron configures a resolver from the PKG_CONFIG_*
+// variables of an environment in os.Environ form
+func NewResolverFromEnviron(environ []string) *Resolver {
+	vars := map[string]string{}
+	for _, kv := range environ {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			vars[key] = value
+		}
+	}
+	r := &Resolver{
+		SysrootDir:        vars["PKG_CONFIG_SYSROOT_DIR"],
+		AllowSystemCflags: vars["PKG_CONFIG_ALLOW_SYSTEM_CFLAGS"] != "",
+		AllowSystemLibs:   vars["PKG_CONFIG_ALLOW_SYSTEM_LIBS"] != "",
+	}
+	r.Path = append(r.Path, splitPath(vars["PKG_CONFIG_PATH"])...)
+	if libDir, ok := vars["PKG_CONFIG_LIBDIR"]; ok {
+		r.Path = append(r.Path, splitPath(libDir)...)
+	} else {
+		r.Path = append(r.Path, defaultPath()...)
+	}
+	return r
+}
+
+func splitPath(s string) []string {
+	var dirs []string
+	for _, dir := range filepath.SplitList(s) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// Find looks up a package by name, or loads it directly from a .pc file path
+func (r *Resolver) Find(name string) (*Package, error) {
+	if pkg, ok := r.cache[name]; ok {
+		return pkg, nil
+	}
+	var pkg *Package
+	var err error
+	if strings.HasSuffix(name, ".pc") {
+		pkg, err = Parse(name)
+	} else {
+		pkg, err = r.search(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.cache == nil {
+		r.cache = map[string]*Package{}
+	}
+	r.cache[name] = pkg
+	return pkg, nil
+}
+
+func (r *Resolver) search(name string) (*Package, error) {
+	for _, dir := range r.Path {
+		path := filepath.Join(dir, name+".pc")
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		return Parse(path)
+	}
+	return nil, fmt.Errorf("Package %s was not found in the pkg-config search path.\n"+
+		"Perhaps you should add the directory containing `%s.pc'\n"+
+		"to the PKG_CONFIG_PATH environment variable", name, name)
+}
+
+// Requirement is a package name with an optional version constraint
+type Requirement struct {
+	Name     string
+	Operator string // one of =, !=, <, <=, >, >=, or empty
+	Version  string
+}
+
+// ParseRequirements parses a Requires field or command line package list
+// such as "foo >= 1.0, bar baz"
+func ParseRequirements(s string) ([]Requirement, error) {
+	fields := strings.Fields(strings.ReplaceAll(s, ",", " "))
+	var reqs []Requirement
+	for i := 0; i < len(fields); i++ {
+		req := Requirement{Name: fields[i]}
+		if i+1 < len(fields) && isOperator(fields[i+1]) {
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("missing version after %s %s", fields[i], fields[i+1])
+			}
+			req.Operator, req.Version = fields[i+1], fields[i+2]
+			i += 2
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+func isOperator(s string) bool {
+	switch s {
+	case "=", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// Satisfied reports whether version meets the requirement
+func (req Requirement) Satisfied(version string) bool {
+	c := CompareVersions(version, req.Version)
+	switch req.Operator {
+	case "":
+		return true
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+// CompareVersions compares versions by their numeric and alphabetic
+// segments, like rpmvercmp which pkg-config uses
+func CompareVersions(a, b string) int {
+	sa, sb := versionSegments(a), versionSegments(b)
+	for i := 0; i < len(sa) && i < len(sb); i++ {
+		x, y := sa[i], sb[i]
+		nx, errX := strconv.Atoi(x)
+		ny, errY := strconv.Atoi(y)
+		switch {
+		case errX == nil && errY == nil:
+			if nx != ny {
+				return sign(nx - ny)
+			}
+		case errX == nil:
+			// Numeric segments are newer than alphabetic ones
+			return 1
+		case errY == nil:
+			return -1
+		default:
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(sa) - len(sb))
+}
+
+func versionSegments(v string) []string {
+	var segs []string
+	start := -1
+	digit := false
+	for i, c := range v {
+		isDigit := c >= '0' && c <= '9'
+		isAlpha := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+		if !isDigit && !isAlpha {
+			if start >= 0 {
+				segs = append(segs, v[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && isDigit != digit {
+			segs = append(segs, v[start:i])
+			start = -1
+		}
+		if start < 0 {
+			start, digit = i, isDigit
+		}
+	}
+	if start >= 0 {
+		segs = append(segs, v[start:])
+	}
+	return segs
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// Resolve looks up the required packages and all packages they require,
+// checking version constraints. The result lists each package once, before
+// the packages it requires. Requires.private is followed only when static.
+func (r *Resolver) Resolve(reqs []Requirement, static bool) ([]*Package, error) {
+	var result []*Package
+	seen := map[string]bool{}
+	var visit func(req Requirement, stack []string) error
+	visit = func(req Requirement, stack []string) error {
+		pkg, err := r.Find(req.Name)
+		if err != nil {
+			return err
+		}
+		if !req.Satisfied(pkg.Version()) {
+			return fmt.Errorf("Requested '%s %s %s' but version of %s is %s",
+				req.Name, req.Operator, req.Version, req.Name, pkg.Version())
+		}
+		for _, name := range stack {
+			if name == pkg.Path {
+				return fmt.Errorf("circular requirement on %s", req.Name)
+			}
+		}
+		if seen[pkg.Path] {
+			return nil
+		}
+		seen[pkg.Path] = true
+
+		fields := []string{"Requires"}
+		if static {
+			fields = append(fields, "Requires.private")
+		}
+		for _, field := range fields {
+			deps, err := ParseRequirements(pkg.Field(field))
+			if err != nil {
+				return fmt.Errorf("%s: %v", pkg.Path, err)
+			}
+			for _, dep := range deps {
+				if err := visit(dep, append(stack, pkg.Path)); err != nil {
+					return err
+				}
+			}
+		}
+		result = append(result, pkg)
+		return nil
+	}
+	for _, req := range reqs {
+		if err := visit(req, nil); err != nil {
+			return nil, err
+		}
+	}
+	// Dependencies were added after everything they require; linkers need
+	// the reverse, so each package comes before its requirements
+	slices.Reverse(result)
+	return result, nil
+}
+
+// Cflags returns the compiler flags of the packages
+func (r *Resolver) Cflags(pkgs []*Package) ([]string, error) {
+	var flags []string
+	for _, pkg := range pkgs {
+		fields, err := splitFlags(pkg.Field("Cflags"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", pkg.Path, err)
+		}
+		for _, flag := range fields {
+			if dir, ok := strings.CutPrefix(flag, "-I"); ok {
+				if !r.AllowSystemCflags && isSystemDir(dir, systemIncludeDirs) {
+					continue
+				}
+				flag = "-I" + r.sysroot(dir)
+			}
+			flags = append(flags, flag)
+		}
+	}
+	return dedupe(flags), nil
+}
+
+// Libs returns the linker flags of the packages, including Libs.private when static
+func (r *Resolver) Libs(pkgs []*Package, static bool) ([]string, error) {
+	var flags []string
+	for _, pkg := range pkgs {
+		value := pkg.Field("Libs")
+		if static {
+			value += " " + pkg.Field("Libs.private")
+		}
+		fields, err := splitFlags(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", pkg.Path, err)
+		}
+		for _, flag := range fields {
+			if dir, ok := strings.CutPrefix(flag, "-L"); ok {
+				if !r.AllowSystemLibs && isSystemDir(dir, systemLibDirs) {
+					continue
+				}
+				flag = "-L" + r.sysroot(dir)
+			}
+			flags = append(flags, flag)
+		}
+	}
+	return dedupeLibs(flags), nil
+}
+
+var (
+	systemIncludeDirs = []string{"/usr/include"}
+	systemLibDirs     = []string{"/usr/lib", "/lib", "/usr/lib64", "/lib64"}
+)
+
+func isSystemDir(dir string, systemDirs []string) bool {
+	if len(systemDirs) > 1 {
+		for _, triple := range multiarchTriples() {
+			systemDirs = append(systemDirs, "/usr/lib/"+triple, "/lib/"+triple)
+		}
+	}
+	for _, d := range systemDirs {
+		if filepath.ToSlash(filepath.Clean(dir)) == d {
+			return true
+		}
+	}
+	return false
+}
+
+// sysroot prepends SysrootDir to absolute paths
+func (r *Resolver) sysroot(dir string) string {
+	if r.SysrootDir == "" || !strings.HasPrefix(dir, "/") {
+		return dir
+	}
+	return filepath.ToSlash(r.SysrootDir) + dir
+}
+
+// dedupe drops repeated flags, keeping the first
+func dedupe(flags []string) []string {
+	seen := map[string]bool{}
+	result := flags[:0:0]
+	for _, flag := range flags {
+		if !seen[flag] {
+			seen[flag] = true
+			result = append(result, flag)
+		}
+	}
+	return result
+}
+
+// dedupeLibs drops repeated -L flags, keeping the first, and repeated
+// libraries, keeping the last so dependencies stay after their users
+func dedupeLibs(flags []string) []string {
+	last := map[string]int{}
+	for i, flag := range flags {
+		last[flag] = i
+	}
+	seen := map[string]bool{}
+	result := flags[:0:0]
+	for i, flag := range flags {
+		if strings.HasPrefix(flag, "-L") {
+			if seen[flag] {
+				continue
+			}
+			seen[flag] = true
+		} else if strings.HasPrefix(flag, "-l") && last[flag] != i {
+			continue
+		}
+		result = append(result, flag)
+	}
+	return result
+}
+
+// splitFlags splits a field into flags, honoring quotes and backslash escapes
+func splitFlags(s string) ([]string, error) {
+	var flags []string
+	var cur strings.Builder
+	inFlag := false
+	var quote rune
+	escaped := false
+	for _, c := range s {
+		switch {
+		case escaped:
+			cur.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped = true
+			inFlag = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			inFlag = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inFlag {
+				flags = append(flags, cur.String())
+				cur.Reset()
+				inFlag = false
+			}
+		default:
+			cur.WriteRune(c)
+			inFlag = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	if inFlag {
+		flags = append(flags, cur.String())
+	}
+	return flags, nil
+}
+
+// Quote joins flags into a line that cgo splits back into the same flags
+func Quote(flags []string) string {
+	quoted := make([]string, len(flags))
+	for i, flag := range flags {
+		var b strings.Builder
+		for _, c := range flag {
+			if strings.ContainsRune(" \t\"'\\$`", c) {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(c)
+		}
+		quoted[i] = b.String()
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,157 @@
+package rungo
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ExtractFlag removes a got specific flag such as --check-glibc from args
+// passed through to go, accepting both "--name value" and "--name=value"
+// with one or two dashes. It returns the flag value, the remaining args and
+// whether the flag was present.
+func ExtractFlag(args []string, name string) (string, []string, bool) {
+	name = strings.TrimLeft(name, "-")
+	var value string
+	found := false
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		flag := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if !strings.HasPrefix(arg, "-") || (flag != name && !strings.HasPrefix(flag, name+"=")) {
+			rest = append(rest, arg)
+			continue
+		}
+		found = true
+		if v, ok := strings.CutPrefix(flag, name+"="); ok {
+			value = v
+		} else if i+1 < len(args) {
+			value = args[i+1]
+			i++
+		}
+	}
+	return value, rest, found
+}
+
+// ExtractBoolFlag removes a got specific boolean flag such as --direct-cgo-flags
+// from args passed through to go, accepting "--name" and "--name=true|false"
+func ExtractBoolFlag(args []string, name string) (bool, []string, error) {
+	name = strings.TrimLeft(name, "-")
+	value := false
+	rest := make([]string, 0, len(args))
+	for i, arg := range args {
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		flag := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if !strings.HasPrefix(arg, "-") || (flag != name && !strings.HasPrefix(flag, name+"=")) {
+			rest = append(rest, arg)
+			continue
+		}
+		value = true
+		if v, ok := strings.CutPrefix(flag, name+"="); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return false, nil, fmt.Errorf("invalid boolean value %q for --%s", v, name)
+			}
+			value = b
+		}
+	}
+	return value, rest, nil
+}
+
+// BuildOutput returns the file go build writes for args: the -o value, or the
+// executable named after the package's import path in the working directory
+func BuildOutput(args []string) (string, error) {
+	goos := os.Getenv("GOOS")
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	ext := ""
+	if goos == "windows" {
+		ext = ".exe"
+	}
+
+	pkgPath := "."
+	if idx := FindPackageIndex(args); idx >= 0 {
+		pkgPath = args[idx]
+	}
+	name, err := execName(pkgPath)
+	if err != nil {
+		return "", err
+	}
+	name += ext
+
+	for i := 0; i < len(args); i++ {
+		var output string
+		if args[i] == "-o" && i+1 < len(args) {
+			output = args[i+1]
+		} else if v, ok := strings.CutPrefix(args[i], "-o="); ok {
+			output = v
+		} else {
+			continue
+		}
+		// go build writes into existing directories and paths ending in a separator
+		if strings.HasSuffix(output, "/") || strings.HasSuffix(output, string(filepath.Separator)) {
+			return filepath.Join(output, name), nil
+		}
+		if fi, err := os.Stat(output); err == nil && fi.IsDir() {
+			return filepath.Join(output, name), nil
+		}
+		return output, nil
+	}
+	return name, nil
+}
+
+// execName returns the name go build gives the executable of pkgPath: the
+// first file without .go, or the last element of the package's import path
+// skipping a major version suffix such as v2
+func execName(pkgPath string) (string, error) {
+	if strings.HasSuffix(pkgPath, ".go") {
+		return strings.TrimSuffix(filepath.Base(pkgPath), ".go"), nil
+	}
+	cmd := exec.Command("go", "list", "-e", "-f", "{{.ImportPath}}", pkgPath)
+	if fi, err := os.Stat(pkgPath); err == nil && fi.IsDir() {
+		cmd = exec.Command("go", "list", "-e", "-f", "{{.ImportPath}}", ".")
+		cmd.Dir = pkgPath
+	}
+	out, err := cmd.Output()
+	importPath, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	if err != nil || importPath == "" {
+		// Fall back to the directory name without a usable go command
+		pkgDir, err := GetPackageDir(pkgPath)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Base(pkgDir), nil
+	}
+	elem := path.Base(importPath)
+	if elem != importPath && isVersionElement(elem) {
+		elem = path.Base(path.Dir(importPath))
+	}
+	return elem, nil
+}
+
+// isVersionElement reports whether s is a major version suffix of a module
+// path, v2 or later
+func isVersionElement(s string) bool {
+	if len(s) < 2 || s[0] != 'v' || s[1] == '0' || s[1] == '1' && len(s) == 2 {
+		return false
+	}
+	for _, c := range s[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+package rungo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExtractFlag(t *testing.T) {
+	tests := []struct {
+		args      []string
+		wantValue string
+		wantRest  []string
+		wantFound bool
+	}{
+		{[]string{"-o", "app", "."}, "", []string{"-o", "app", "."}, false},
+		{[]string{"--check-glibc", "2.28", "."}, "2.28", []string{"."}, true},
+		{[]string{"-check-glibc=2.17", "-v"}, "2.17", []string{"-v"}, true},
+		{[]string{".", "--", "--check-glibc", "2.28"}, "", []string{".", "--", "--check-glibc", "2.28"}, false},
+		{[]string{"--check-glibcx", "."}, "", []string{"--check-glibcx", "."}, false},
+	}
+	for _, tt := range tests {
+		value, rest, found := ExtractFlag(tt.args, "check-glibc")
+		if value != tt.wantValue || found != tt.wantFound || !reflect.DeepEqual(rest, tt.wantRest) {
+			t.Errorf("ExtractFlag(%q) = %q, %q, %v, want %q, %q, %v",
+				tt.args, value, rest, found, tt.wantValue, tt.wantRest, tt.wantFound)
+		}
+	}
+}
+
+func TestExtractBoolFlag(t *testing.T) {
+	tests := []struct {
+		args      []string
+		wantValue bool
+		wantRest  []string
+		wantErr   bool
+	}{
+		{[]string{"-o", "app", "."}, false, []string{"-o", "app", "."}, false},
+		{[]string{"--direct-cgo-flags", "."}, true, []string{"."}, false},
+		{[]string{"-direct-cgo-flags=false", "."}, false, []string{"."}, false},
+		{[]string{".", "--", "--direct-cgo-flags"}, false, []string{".", "--", "--direct-cgo-flags"}, false},
+		{[]string{"--direct-cgo-flags=maybe"}, false, nil, true},
+	}
+	for _, tt := range tests {
+		value, rest, err := ExtractBoolFlag(tt.args, "direct-cgo-flags")
+		if (err != nil) != tt.wantErr || value != tt.wantValue || !reflect.DeepEqual(rest, tt.wantRest) {
+			t.Errorf("ExtractBoolFlag(%q) = %v, %q, %v, want %v, %q, error %v",
+				tt.args, value, rest, err, tt.wantValue, tt.wantRest, tt.wantErr)
+		}
+	}
+}
+
+func TestBuildOutput(t *testing.T) {
+	dir := t.TempDir()
+	pkgDir := filepath.Join(dir, "myapp")
+	outDir := filepath.Join(dir, "out")
+	for _, d := range []string{pkgDir, outDir} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("GOOS", "linux")
+
+	// Executables are named after the import path, not the directory
+	modules := map[string]string{"checkout": "example.com/tool", "tool-v2": "example.com/tool/v2"}
+	for name, module := range modules {
+		d := filepath.Join(dir, name)
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(d, "go.mod"), []byte("module "+module+"\n\ngo 1.21\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(d, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{pkgDir}, "myapp"},
+		{[]string{filepath.Join(dir, "checkout")}, "tool"},
+		{[]string{filepath.Join(dir, "tool-v2")}, "tool"},
+		{[]string{filepath.Join(dir, "checkout", "main.go")}, "main"},
+		{[]string{"-o", "bin/app", pkgDir}, "bin/app"},
+		{[]string{"-o=bin/app", pkgDir}, "bin/app"},
+		{[]string{"-o", outDir, pkgDir}, filepath.Join(outDir, "myapp")},
+		{[]string{"-o", "dist/", pkgDir}, filepath.Join("dist", "myapp")},
+	}
+	for _, tt := range tests {
+		got, err := BuildOutput(tt.args)
+		if err != nil {
+			t.Errorf("BuildOutput(%q) error = %v", tt.args, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("BuildOutput(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestIsVersionElement(t *testing.T) {
+	tests := map[string]bool{"v2": true, "v10": true, "v1": false, "v0": false, "v": false, "v2beta": false, "tool": false}
+	for s, want := range tests {
+		if got := isVersionElement(s); got != want {
+			t.Errorf("isVersionElement(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package rungo
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+
+	"github.com/gotray/got/internal/env"
+)
+
+// CgoFlags are the compiler and linker flags for embedding Python
+type CgoFlags struct {
+	CFLAGS  []string
+	LDFLAGS []string
+}
+
+// Env returns the flags as CGO_CFLAGS and CGO_LDFLAGS values
+func (f *CgoFlags) Env() (map[string]string, error) {
+	cflags, err := joinQuoted(f.CFLAGS)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CGO_CFLAGS: %v", err)
+	}
+	ldflags, err := joinQuoted(f.LDFLAGS)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CGO_LDFLAGS: %v", err)
+	}
+	return map[string]string{
+		"CGO_CFLAGS":  cflags,
+		"CGO_LDFLAGS": ldflags,
+	}, nil
+}
+
+// PythonCgoFlags returns the flags for embedding the Python installed as
+// layout, read from its build metadata instead of pkg-config
+func PythonCgoFlags(layout env.PythonLayout, goos string) (*CgoFlags, error) {
+	info, err := layout.Info()
+	if err != nil {
+		return nil, err
+	}
+	return pythonCgoFlags(layout, info, goos), nil
+}
+
+// pythonCgoFlags returns the flags for the Python described by info,
+// installed as layout, when building for goos
+func pythonCgoFlags(layout env.PythonLayout, info *env.PythonInfo, goos string) *CgoFlags {
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	libDir := layout.LibDir
+	if goos == "windows" {
+		// python-build-standalone keeps the DLL in the installation root
+		libDir = layout.Home
+	}
+	flags := &CgoFlags{
+		CFLAGS:  []string{"-I" + filepath.ToSlash(layout.IncludeDir(info))},
+		LDFLAGS: []string{"-L" + filepath.ToSlash(libDir), "-l" + info.LibName()},
+	}
+	if info.LinkMode == "static" {
+		flags.LDFLAGS = append(flags.LDFLAGS, info.StaticLinkFlags("")...)
+	}
+	return flags
+}
+
+// PythonStaticCgoFlags returns the flags for linking the static libpython of
+// the Python installed as layout, with python-build-standalone's build
+// libraries in buildLibDir
+func PythonStaticCgoFlags(layout env.PythonLayout, buildLibDir string) (*CgoFlags, error) {
+	info, err := layout.Info()
+	if err != nil {
+		return nil, err
+	}
+	lib := env.StaticLibPython(layout, info, buildLibDir)
+	if lib == "" {
+		return nil, fmt.Errorf("no static libpython found for Python %s in %s", info.Version, layout.Home)
+	}
+	return &CgoFlags{
+		CFLAGS:  []string{"-I" + filepath.ToSlash(layout.IncludeDir(info))},
+		LDFLAGS: append([]string{filepath.ToSlash(lib)}, info.StaticLinkFlags(buildLibDir)...),
+	}, nil
+}
@@ -0,0 +1,130 @@
+package rungo
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/mattn/go-isatty"
+)
+
+// diagnosis recognizes a well-known failure in the output of a command
+type diagnosis struct {
+	pattern *regexp.Regexp
+	hint    func(match []string) string
+}
+
+var diagnoses = []diagnosis{
+	{
+		pattern: regexp.MustCompile(`(?:exec: )?"?\b(gcc|cc|clang)"?(?:: executable file not found in \$PATH|: not found|: command not found)`),
+		hint: func(m []string) string {
+			return fmt.Sprintf("cgo needs a C compiler but %s was not found: %s, or set CC", m[1], installCompilerHint())
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`"?pkg-config"?: (?:executable file not found in \$PATH|not found|command not found)`),
+		hint: func([]string) string {
+			return "cgo needs pkg-config to find Python: install it (e.g. apt install pkg-config or brew install pkg-config)"
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`Package '?(python-?3[\w.-]*)'?,? (?:was not found|required by .* not found|not found)`),
+		hint: func(m []string) string {
+			return fmt.Sprintf("pkg-config cannot find %s in the project's Python: re-run `got init .` to regenerate .deps/python/lib/pkgconfig, or run `got doctor`", m[1])
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`(libpython[\w.]*?\.(?:so[\w.]*|dylib)): cannot open shared object file|Library not loaded: \S*?(libpython[\w.]*?\.dylib)`),
+		hint: func(m []string) string {
+			lib := m[1]
+			if lib == "" {
+				lib = m[2]
+			}
+			return fmt.Sprintf("the program cannot load %s: start it with `got run` or `got exec`, or rebuild it with `got build` so its rpath points at the project's Python", lib)
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`ModuleNotFoundError: No module named '([\w.]+)'`),
+		hint: func(m []string) string {
+			module, _, _ := strings.Cut(m[1], ".")
+			return fmt.Sprintf("Python module %s is missing: pip-install it into .deps/python with `got exec python -m pip install %s` (the pip package name may differ from the module name)", module, module)
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`Fatal Python error: init_fs_encoding`),
+		hint: func([]string) string {
+			return "Python cannot find its standard library, .deps/env.txt is probably stale: re-run `got init .` or run `got doctor`"
+		},
+	},
+}
+
+func installCompilerHint() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "install the Xcode command line tools with xcode-select --install"
+	case "windows":
+		return "re-run `got init .` to install MinGW"
+	default:
+		return "install gcc (e.g. apt install build-essential, dnf install gcc or apk add build-base) or re-run `got init . --c-toolchain zig`"
+	}
+}
+
+// Diagnose returns a hint for each well-known failure found in output
+func Diagnose(output string) []string {
+	var hints []string
+	seen := map[string]bool{}
+	for _, d := range diagnoses {
+		for _, m := range d.pattern.FindAllStringSubmatch(output, -1) {
+			hint := d.hint(m)
+			if !seen[hint] {
+				seen[hint] = true
+				hints = append(hints, hint)
+			}
+		}
+	}
+	return hints
+}
+
+// maxTailSize bounds the output kept for diagnosis
+const maxTailSize = 64 * 1024
+
+// tailWriter keeps the last maxTailSize bytes written to it
+type tailWriter struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > maxTailSize {
+		w.buf = append(w.buf[:0], w.buf[len(w.buf)-maxTailSize:]...)
+	}
+	return len(p), nil
+}
+
+func (w *tailWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return string(w.buf)
+}
+
+// isTerminal reports whether f is a terminal
+var isTerminal = func(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// childStderr returns the stderr of a child process writing to f and keeping
+// its tail in tail. Interactive commands, such as a program run by got run or
+// got exec, get a terminal f as is to keep colored output and prompts working.
+func childStderr(f *os.File, tail *tailWriter, interactive bool) io.Writer {
+	if interactive && isTerminal(f) {
+		return f
+	}
+	return io.MultiWriter(f, tail)
+}
@@ -0,0 +1,134 @@
+package rungo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiagnose(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{
+			name:   "missing gcc",
+			output: "# runtime/cgo\ncgo: C compiler \"gcc\" not found: exec: \"gcc\": executable file not found in $PATH\n",
+			want:   []string{"C compiler but gcc was not found"},
+		},
+		{
+			name:   "missing python3-embed",
+			output: "# pkg-config --cflags  -- python3-embed\nPackage python3-embed was not found in the pkg-config search path.\n",
+			want:   []string{"cannot find python3-embed", "got init"},
+		},
+		{
+			name:   "missing pkg-config",
+			output: "go build github.com/gotray/go-python: invalid flag in pkg-config --cflags: exec: \"pkg-config\": executable file not found in $PATH",
+			want:   []string{"needs pkg-config"},
+		},
+		{
+			name:   "libpython not loadable on Linux",
+			output: "./app: error while loading shared libraries: libpython3.13.so.1.0: cannot open shared object file: No such file or directory\n",
+			want:   []string{"cannot load libpython3.13.so.1.0"},
+		},
+		{
+			name:   "libpython not loadable on macOS",
+			output: "dyld[123]: Library not loaded: @rpath/libpython3.12.dylib\n  Referenced from: /tmp/app\n",
+			want:   []string{"cannot load libpython3.12.dylib"},
+		},
+		{
+			name:   "missing pip package",
+			output: "Traceback (most recent call last):\n  File \"<string>\", line 1, in <module>\nModuleNotFoundError: No module named 'numpy.linalg'\n",
+			want:   []string{"module numpy is missing", "pip install numpy"},
+		},
+		{
+			name:   "stale env.txt",
+			output: "Fatal Python error: init_fs_encoding: failed to get the Python codec of the filesystem encoding\n",
+			want:   []string{"env.txt is probably stale"},
+		},
+		{
+			name:   "unrelated failure",
+			output: "./main.go:5:2: undefined: foo\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hints := Diagnose(tt.output)
+			if len(tt.want) == 0 {
+				if len(hints) != 0 {
+					t.Errorf("Diagnose() = %q, want no hints", hints)
+				}
+				return
+			}
+			if len(hints) != 1 {
+				t.Fatalf("Diagnose() = %q, want one hint", hints)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(hints[0], want) {
+					t.Errorf("Diagnose() = %q, want it to contain %q", hints[0], want)
+				}
+			}
+		})
+	}
+}
+
+func TestTailWriter(t *testing.T) {
+	w := &tailWriter{}
+	w.Write([]byte(strings.Repeat("x", maxTailSize)))
+	w.Write([]byte("ModuleNotFoundError: No module named 'yaml'"))
+	out := w.String()
+	if len(out) != maxTailSize || !strings.HasSuffix(out, "'yaml'") {
+		t.Errorf("tailWriter kept %d bytes ending in %q", len(out), out[len(out)-10:])
+	}
+}
+
+func TestChildStderr(t *testing.T) {
+	if _, err := os.Stat("/proc/self/fd/2"); err != nil {
+		t.Skip("needs /proc/self/fd")
+	}
+	f, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// stderr reports what the child's stderr refers to and the tail kept
+	stderr := func(terminal, interactive bool) (string, string) {
+		saved := isTerminal
+		isTerminal = func(*os.File) bool { return terminal }
+		defer func() { isTerminal = saved }()
+
+		tail := &tailWriter{}
+		cmd := exec.Command("sh", "-c", "readlink /proc/self/fd/2; echo 'gcc: not found' >&2")
+		cmd.Stderr = childStderr(f, tail, interactive)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("sh error = %v", err)
+		}
+		return strings.TrimSpace(string(out)), tail.String()
+	}
+	tests := []struct {
+		terminal, interactive bool
+		wantRaw               bool
+	}{
+		{terminal: true, interactive: true, wantRaw: true},
+		{terminal: true, interactive: false},
+		{terminal: false, interactive: true},
+		{terminal: false, interactive: false},
+	}
+	for _, tt := range tests {
+		got, tail := stderr(tt.terminal, tt.interactive)
+		if tt.wantRaw {
+			if got != f.Name() {
+				t.Errorf("terminal %v, interactive %v: child stderr = %q, want %q", tt.terminal, tt.interactive, got, f.Name())
+			}
+			continue
+		}
+		if !strings.HasPrefix(got, "pipe:") || !strings.Contains(tail, "gcc: not found") {
+			t.Errorf("terminal %v, interactive %v: child stderr = %q with tail %q, want a pipe keeping its tail", tt.terminal, tt.interactive, got, tail)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package rungo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// linkerValueFlags are the go tool link flags taking a separate value
+var linkerValueFlags = map[string]bool{
+	"B": true, "E": true, "H": true, "I": true, "L": true, "R": true, "T": true,
+	"benchmark": true, "benchmarkprofile": true, "buildid": true, "buildmode": true,
+	"cpuprofile": true, "extar": true, "extld": true, "importcfg": true,
+	"installsuffix": true, "k": true, "libgcc": true, "linkmode": true,
+	"memprofile": true, "memprofilerate": true, "o": true, "pluginpath": true,
+	"r": true, "tmpdir": true,
+}
+
+// XDef is a -X importpath.name=value string variable definition
+type XDef struct {
+	Name  string
+	Value string
+}
+
+// LDFlags is a parsed -ldflags value. -X definitions and -extldflags are kept
+// apart so that flags from several sources can be merged into one value.
+type LDFlags struct {
+	Flags      []string // other linker flags, each followed by its value if any
+	X          []XDef   // string definitions, one per name
+	ExtLDFlags []string // flags passed to the external linker
+}
+
+// ParseLDFlags parses a -ldflags value, quoted like go build quotes it
+func ParseLDFlags(s string) (*LDFlags, error) {
+	args, err := splitQuoted(s)
+	if err != nil {
+		return nil, err
+	}
+	f := &LDFlags{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			f.Flags = append(f.Flags, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-"), "=")
+		if name != "X" && name != "extldflags" && !linkerValueFlags[name] {
+			f.Flags = append(f.Flags, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("missing value for linker flag %s", arg)
+			}
+			i++
+			value = args[i]
+		}
+		switch name {
+		case "X":
+			defName, defValue, ok := strings.Cut(value, "=")
+			if !ok {
+				return nil, fmt.Errorf("-X flag requires argument of the form importpath.name=value, got %q", value)
+			}
+			f.SetX(defName, defValue)
+		case "extldflags":
+			extFlags, err := splitQuoted(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid -extldflags: %v", err)
+			}
+			f.ExtLDFlags = append(f.ExtLDFlags, extFlags...)
+		default:
+			f.Flags = append(f.Flags, "-"+name, value)
+		}
+	}
+	return f, nil
+}
+
+// SetX defines the string variable name, replacing an earlier definition
+func (f *LDFlags) SetX(name, value string) {
+	for i := range f.X {
+		if f.X[i].Name == name {
+			f.X[i].Value = value
+			return
+		}
+	}
+	f.X = append(f.X, XDef{Name: name, Value: value})
+}
+
+// Merge adds the flags of other. Its -X definitions override those of f and
+// its external linker flags are appended.
+func (f *LDFlags) Merge(other *LDFlags) {
+	f.Flags = append(f.Flags, other.Flags...)
+	for _, def := range other.X {
+		f.SetX(def.Name, def.Value)
+	}
+	f.ExtLDFlags = append(f.ExtLDFlags, other.ExtLDFlags...)
+}
+
+// String returns the flags as a single -ldflags value for display, see Value
+func (f *LDFlags) String() string {
+	value, _ := f.Value()
+	return value
+}
+
+// Value returns the flags as a single -ldflags value, failing for flags go
+// can't split back into the same fields
+func (f *LDFlags) Value() (string, error) {
+	args := append([]string{}, f.Flags...)
+	for _, def := range f.X {
+		args = append(args, "-X", def.Name+"="+def.Value)
+	}
+	var extErr error
+	if len(f.ExtLDFlags) > 0 {
+		var extFlags string
+		extFlags, extErr = joinQuoted(f.ExtLDFlags)
+		args = append(args, "-extldflags", extFlags)
+	}
+	value, err := joinQuoted(args)
+	if extErr != nil {
+		err = extErr
+	}
+	if err != nil {
+		return value, fmt.Errorf("invalid -ldflags: %v", err)
+	}
+	return value, nil
+}
+
+// splitQuoted splits s into fields separated by spaces, where single or
+// double quotes group a field, like go build splits flag values
+func splitQuoted(s string) ([]string, error) {
+	var fields []string
+	for {
+		s = strings.TrimLeft(s, " \t\n\r")
+		if s == "" {
+			return fields, nil
+		}
+		if s[0] == '\'' || s[0] == '"' {
+			end := strings.IndexByte(s[1:], s[0])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated %c string", s[0])
+			}
+			fields = append(fields, s[1:end+1])
+			s = s[end+2:]
+			continue
+		}
+		end := strings.IndexAny(s, " \t\n\r")
+		if end < 0 {
+			end = len(s)
+		}
+		fields = append(fields, s[:end])
+		s = s[end:]
+	}
+}
+
+// joinQuoted joins fields so that splitQuoted returns them again. Quoted
+// fields have no escapes, so a field containing both ' and " can't be
+// joined; the error names it and the result quotes it as well as possible.
+func joinQuoted(fields []string) (string, error) {
+	var err error
+	quoted := make([]string, len(fields))
+	for i, field := range fields {
+		switch {
+		case field != "" && !strings.ContainsAny(field, " \t\n\r'\""):
+			quoted[i] = field
+		case !strings.Contains(field, "'"):
+			quoted[i] = "'" + field + "'"
+		default:
+			if strings.Contains(field, `"`) && err == nil {
+				err = fmt.Errorf("%q contains both ' and \", which can't be quoted", field)
+			}
+			quoted[i] = `"` + field + `"`
+		}
+	}
+	return strings.Join(quoted, " "), err
+}
@@ -0,0 +1,171 @@
+package rungo
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gotray/got/internal/env"
+)
+
+func TestParseLDFlags(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    *LDFlags
+		wantErr bool
+	}{
+		{"-s -w", &LDFlags{Flags: []string{"-s", "-w"}}, false},
+		{
+			`-X main.version=1.0 -X 'main.name=my app' -linkmode external`,
+			&LDFlags{Flags: []string{"-linkmode", "external"}, X: []XDef{{"main.version", "1.0"}, {"main.name", "my app"}}},
+			false,
+		},
+		{
+			`-extldflags=-static -extldflags "-Wl,-z,relro '-L/a b'"`,
+			&LDFlags{ExtLDFlags: []string{"-static", "-Wl,-z,relro", "-L/a b"}},
+			false,
+		},
+		{"-X main.version=1 -X main.version=2", &LDFlags{X: []XDef{{"main.version", "2"}}}, false},
+		{"-X main.version", nil, true},
+		{"-extldflags", nil, true},
+		{"-X 'main.version=1", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLDFlags(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLDFlags(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseLDFlags(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLDFlagsString(t *testing.T) {
+	f := &LDFlags{
+		Flags:      []string{"-s"},
+		X:          []XDef{{"main.name", "my app"}},
+		ExtLDFlags: []string{"-Wl,-rpath,/opt/py lib", "-static"},
+	}
+	want := `-s -X 'main.name=my app' -extldflags "'-Wl,-rpath,/opt/py lib' -static"`
+	if got := f.String(); got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+	parsed, err := ParseLDFlags(f.String())
+	if err != nil || !reflect.DeepEqual(parsed, f) {
+		t.Errorf("ParseLDFlags(String()) = %+v, %v, want %+v", parsed, err, f)
+	}
+	if value, err := f.Value(); err != nil || value != want {
+		t.Errorf("Value() = %s, %v, want %s", value, err, want)
+	}
+
+	// Quoted fields have no escapes, so ' and " can't both be kept
+	for _, f := range []*LDFlags{
+		{X: []XDef{{"main.quote", `it's "quoted"`}}},
+		{ExtLDFlags: []string{`-DNAME="it's"`}},
+	} {
+		if value, err := f.Value(); err == nil {
+			t.Errorf("Value() = %s, want an error for a field with ' and \"", value)
+		}
+	}
+	if _, err := (&CgoFlags{CFLAGS: []string{`-DNAME="it's"`}}).Env(); err == nil {
+		t.Error("Env() error = nil, want an error for a field with ' and \"")
+	}
+}
+
+func TestMergeLDFlags(t *testing.T) {
+	opts := LDFlagsOptions{ProjectRoot: "/proj", PythonLibDir: "/proj/lib", GOOS: "linux"}
+	tests := []struct {
+		args     []string
+		want     string
+		wantRest []string
+	}{
+		{
+			[]string{"-o", "app", "."},
+			"-X github.com/gotray/got.ProjectRoot=/proj -extldflags -Wl,-rpath,/proj/lib",
+			[]string{"-o", "app", "."},
+		},
+		{
+			[]string{"-ldflags", "-s -X main.v=1 -extldflags=-static", "-ldflags=-X github.com/gotray/got.ProjectRoot=/dist", "."},
+			"-s -X github.com/gotray/got.ProjectRoot=/dist -X main.v=1 -extldflags '-Wl,-rpath,/proj/lib -static'",
+			[]string{"."},
+		},
+		{
+			[]string{"--ldflags=all=-w", "."},
+			"-w -X github.com/gotray/got.ProjectRoot=/proj -extldflags -Wl,-rpath,/proj/lib",
+			[]string{"."},
+		},
+		{
+			[]string{".", "--", "-ldflags", "-s"},
+			"-X github.com/gotray/got.ProjectRoot=/proj -extldflags -Wl,-rpath,/proj/lib",
+			[]string{".", "--", "-ldflags", "-s"},
+		},
+	}
+	for _, tt := range tests {
+		got, rest, err := MergeLDFlags(tt.args, opts)
+		if err != nil {
+			t.Errorf("MergeLDFlags(%q) error = %v", tt.args, err)
+			continue
+		}
+		if got.String() != tt.want || !reflect.DeepEqual(rest, tt.wantRest) {
+			t.Errorf("MergeLDFlags(%q) = %s, %q, want %s, %q", tt.args, got, rest, tt.want, tt.wantRest)
+		}
+	}
+
+	windows := opts
+	windows.GOOS = "windows"
+	if got := GotLDFlags(windows); len(got.ExtLDFlags) != 0 {
+		t.Errorf("GotLDFlags(windows) ExtLDFlags = %q, want none", got.ExtLDFlags)
+	}
+	static := opts
+	static.PythonLink = PythonLinkStatic
+	want := "-X github.com/gotray/got.ProjectRoot=/proj -X github.com/gotray/got.PythonLink=static -extldflags -Wl,--export-dynamic"
+	if got := GotLDFlags(static).String(); got != want {
+		t.Errorf("GotLDFlags(static) = %s, want %s", got, want)
+	}
+	stamped := opts
+	stamped.PythonLibDir = ""
+	stamped.Stamps = map[string]string{"PythonVersion": "3.13.1", "GotVersion": "devel"}
+	want = "-X github.com/gotray/got.ProjectRoot=/proj -X github.com/gotray/got.GotVersion=devel -X github.com/gotray/got.PythonVersion=3.13.1"
+	if got := GotLDFlags(stamped).String(); got != want {
+		t.Errorf("GotLDFlags(stamped) = %s, want %s", got, want)
+	}
+	if _, _, err := MergeLDFlags([]string{"-ldflags", "-X broken"}, opts); err == nil {
+		t.Error("MergeLDFlags(-X broken) error = nil, want error")
+	}
+}
+
+func TestPythonCgoFlags(t *testing.T) {
+	home := t.TempDir()
+	layout := env.PythonLayout{Home: home, LibDir: filepath.Join(home, "lib")}
+	info := &env.PythonInfo{MajorMinor: "3.13", ABITag: "t", TargetTriple: "x86_64-unknown-linux-gnu", LinkMode: "shared"}
+	root := filepath.ToSlash(home)
+
+	got := pythonCgoFlags(layout, info, "linux")
+	want := &CgoFlags{
+		CFLAGS:  []string{"-I" + root + "/include/python3.13t"},
+		LDFLAGS: []string{"-L" + root + "/lib", "-lpython3.13t"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pythonCgoFlags(shared) = %+v, want %+v", got, want)
+	}
+
+	info.LinkMode = "static"
+	info.BuildInfo.Core.Links = []env.PythonLink{{Name: "m", System: true}, {Name: "ffi"}, {Name: "CoreFoundation", Framework: true}}
+	got = pythonCgoFlags(layout, info, "linux")
+	want.LDFLAGS = append(want.LDFLAGS, "-lm", "-framework", "CoreFoundation")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pythonCgoFlags(static) = %+v, want %+v", got, want)
+	}
+
+	winInfo := &env.PythonInfo{MajorMinor: "3.13", TargetTriple: "x86_64-pc-windows-msvc", LinkMode: "shared"}
+	got = pythonCgoFlags(layout, winInfo, "windows")
+	want = &CgoFlags{
+		CFLAGS:  []string{"-I" + root + "/include"},
+		LDFLAGS: []string{"-L" + root, "-lpython313"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pythonCgoFlags(windows) = %+v, want %+v", got, want)
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 
+	"github.com/gotray/got/cmd/internal/bundle"
 	"github.com/gotray/got/internal/env"
 )
 
@@ -69,8 +71,7 @@ func GetPackageDir(pkgPath string) (string, error) {
 }
 
 func FindProjectRoot(dir string) (string, error) {
-	env := env.NewPythonEnv(env.GetPythonRoot(dir))
-	_, err := env.Python()
+	_, err := env.GetPythonLayout(dir).PythonEnv().Python()
 	if err == nil {
 		return dir, nil
 	}
@@ -81,8 +82,31 @@ func FindProjectRoot(dir string) (string, error) {
 	return FindProjectRoot(parentDir)
 }
 
+// RunOptions adjusts the environment RunCommandWithOptions runs commands in
+type RunOptions struct {
+	// Env overrides variables of the build environment, e.g. for cross-compiling
+	Env map[string]string
+	// PythonLibDir is the libpython directory added to the rpath, defaults to the project's
+	PythonLibDir string
+	// PythonHome is the root of the Python built against, defaults to the project's
+	PythonHome string
+	// PythonLink is PythonLinkStatic to link libpython into the executable
+	PythonLink string
+	// Bundle builds executables for a got dist bundle, which find the Python
+	// runtime relative to themselves instead of in the project
+	Bundle bool
+	// DirectCgoFlags sets CGO_CFLAGS and CGO_LDFLAGS from the Python build
+	// metadata so that packages build without pkg-config
+	DirectCgoFlags bool
+}
+
 // RunGoCommand executes a Go command with Python environment properly configured
 func RunCommand(command string, args []string) error {
+	return RunCommandWithOptions(command, args, RunOptions{})
+}
+
+// RunCommandWithOptions executes a command like RunCommand with the environment adjusted by opts
+func RunCommandWithOptions(command string, args []string, opts RunOptions) error {
 	wd, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("failed to get working directory: %v", err)
@@ -92,37 +116,74 @@ func RunCommand(command string, args []string) error {
 		return fmt.Errorf("should run this command in a Got project: %v", err)
 	}
 	env.SetBuildEnv(projectRoot)
+	for key, value := range opts.Env {
+		os.Setenv(key, value)
+	}
 
 	// Set up environment variables
 	goEnv := []string{}
 	// Get PYTHONPATH and PYTHONHOME from env.txt
-	var pythonPath, pythonHome string
 	if additionalEnv, err := env.ReadEnv(projectRoot); err == nil {
 		for key, value := range additionalEnv {
 			goEnv = append(goEnv, key+"="+value)
 		}
-		pythonPath = additionalEnv["PYTHONPATH"]
-		pythonHome = additionalEnv["PYTHONHOME"]
 	} else {
 		fmt.Fprintf(os.Stderr, "Warning: could not load environment variables: %v\n", err)
 	}
 
+	// Only go build, test and the like are diagnosed on a terminal
+	interactive := command != "go" || (len(args) > 0 && args[0] == "run")
 	cmdArgs := args
 	if command == "go" {
 		goCmd := args[0]
 		args = args[1:]
+		layout := env.GetPythonLayout(projectRoot)
+		if opts.PythonHome != "" {
+			layout = env.PythonLayout{Home: opts.PythonHome, LibDir: opts.PythonLibDir}
+		} else if opts.PythonLibDir != "" {
+			layout.LibDir = opts.PythonLibDir
+		}
+		ldOpts := LDFlagsOptions{
+			ProjectRoot:  projectRoot,
+			PythonLibDir: layout.LibDir,
+			PythonLink:   opts.PythonLink,
+			GOOS:         os.Getenv("GOOS"),
+			Stamps:       BuildStamps(projectRoot, layout),
+		}
+		if opts.Bundle {
+			goos := ldOpts.GOOS
+			if goos == "" {
+				goos = runtime.GOOS
+			}
+			ldOpts.ProjectRoot = ""
+			ldOpts.BundleRoot = bundle.RootFromBin
+			ldOpts.PythonLibDir = bundle.Rpath(goos)
+		}
 		// Process args to inject Python paths via ldflags
-		cmdArgs = append([]string{goCmd}, ProcessArgsWithLDFlags(args, projectRoot, pythonPath, pythonHome)...)
+		args, err = processArgsWithLDFlags(args, ldOpts)
+		if err != nil {
+			return err
+		}
+		cmdArgs = append([]string{goCmd}, args...)
+
+		if opts.DirectCgoFlags {
+			if err := setCgoFlags(projectRoot, layout, opts.PythonLink); err != nil {
+				return err
+			}
+		}
 	}
 
 	cmd := exec.Command(command, cmdArgs...)
 	cmd.Env = append(goEnv, os.Environ()...)
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	// Keep the tail of stderr to explain well-known failures
+	stderr := &tailWriter{}
+	cmd.Stderr = childStderr(os.Stderr, stderr, interactive)
 	cmd.Stdin = os.Stdin
 
 	// Execute the command
 	if err := cmd.Run(); err != nil {
+		printHints(Diagnose(stderr.String() + "\n" + err.Error()))
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			os.Exit(exitErr.ExitCode())
 		}
@@ -132,45 +193,169 @@ func RunCommand(command string, args []string) error {
 	return nil
 }
 
+// setCgoFlags adds the flags for embedding the Python installed as layout to
+// CGO_CFLAGS and CGO_LDFLAGS, keeping the flags already set
+func setCgoFlags(projectRoot string, layout env.PythonLayout, pythonLink string) error {
+	var flags *CgoFlags
+	var err error
+	if pythonLink == PythonLinkStatic {
+		flags, err = PythonStaticCgoFlags(layout, env.GetPythonBuildLibDir(projectRoot))
+	} else {
+		flags, err = PythonCgoFlags(layout, os.Getenv("GOOS"))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get Python build flags: %v", err)
+	}
+	cgoEnv, err := flags.Env()
+	if err != nil {
+		return err
+	}
+	for key, value := range cgoEnv {
+		if existing := os.Getenv(key); existing != "" {
+			value = existing + " " + value
+		}
+		os.Setenv(key, value)
+	}
+	return nil
+}
+
+// printHints prints hints for the failures found by Diagnose
+func printHints(hints []string) {
+	for _, hint := range hints {
+		fmt.Fprintf(os.Stderr, "got: hint: %s\n", hint)
+	}
+}
+
 // ProcessArgsWithLDFlags processes command line arguments to inject Python paths via ldflags
 func ProcessArgsWithLDFlags(args []string, projectRoot, pythonPath, pythonHome string) []string {
-	result := make([]string, 0, len(args))
+	result, err := processArgsWithLDFlags(args, LDFlagsOptions{
+		ProjectRoot:  projectRoot,
+		PythonLibDir: env.GetPythonLayout(projectRoot).LibDir,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return args
+	}
+	return result
+}
 
-	// Prepare the -X flags we want to add
-	ldflags := fmt.Sprintf("-X 'github.com/gotray/got.ProjectRoot=%s'", projectRoot)
+// Python link modes of got build --python-link
+const (
+	PythonLinkShared = "shared"
+	PythonLinkStatic = "static"
+)
+
+// LDFlagsOptions describes the linker flags got adds to go commands
+type LDFlagsOptions struct {
+	ProjectRoot  string // recorded in github.com/gotray/got.ProjectRoot if set
+	PythonLibDir string // added to the rpath if set
+	PythonLink   string // PythonLinkStatic when libpython is linked into the executable
+	BundleRoot   string // recorded in github.com/gotray/got.BundleRoot if set
+	GOOS         string // target OS, defaults to the host
+	// Stamps are further variables of package got to set, e.g. from BuildStamps
+	Stamps map[string]string
+}
+
+// GotLDFlags returns the linker flags got adds for opts
+func GotLDFlags(opts LDFlagsOptions) *LDFlags {
+	f := &LDFlags{}
+	if opts.ProjectRoot != "" {
+		f.SetX("github.com/gotray/got.ProjectRoot", opts.ProjectRoot)
+	}
+	if opts.BundleRoot != "" {
+		f.SetX("github.com/gotray/got.BundleRoot", opts.BundleRoot)
+	}
+	names := make([]string, 0, len(opts.Stamps))
+	for name := range opts.Stamps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f.SetX(stampPackage+"."+name, opts.Stamps[name])
+	}
 
-	// Prepare rpath flag if needed
-	pythonLibDir := env.GetPythonLibDir(projectRoot)
-	switch runtime.GOOS {
+	goos := opts.GOOS
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if opts.PythonLink == PythonLinkStatic {
+		// The executable needs PYTHONHOME pointing at a bundled standard
+		// library instead of an rpath to libpython
+		f.SetX("github.com/gotray/got.PythonLink", PythonLinkStatic)
+		if goos != "darwin" && goos != "windows" {
+			// Extension modules resolve the Python C API from the executable
+			f.ExtLDFlags = append(f.ExtLDFlags, "-Wl,--export-dynamic")
+		}
+		return f
+	}
+	if opts.PythonLibDir == "" {
+		return f
+	}
+	switch goos {
 	case "darwin", "linux":
-		ldflags += fmt.Sprintf(" -extldflags '-Wl,-rpath,%s'", pythonLibDir)
+		f.ExtLDFlags = append(f.ExtLDFlags, "-Wl,-rpath,"+opts.PythonLibDir)
 	case "windows":
 		// Windows doesn't use rpath
 	default:
 		// Use Linux format for other Unix-like systems
-		ldflags += fmt.Sprintf(" -extldflags '-Wl,-rpath=%s'", pythonLibDir)
+		f.ExtLDFlags = append(f.ExtLDFlags, "-Wl,-rpath="+opts.PythonLibDir)
 	}
+	return f
+}
 
+// MergeLDFlags merges the -ldflags values in args into got's flags for opts
+// and returns the remaining args
+func MergeLDFlags(args []string, opts LDFlagsOptions) (*LDFlags, []string, error) {
+	ldflags := GotLDFlags(opts)
+	rest := make([]string, 0, len(args))
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
-		if strings.HasPrefix(arg, "-ldflags=") || arg == "-ldflags" {
-			// Get existing flags
-			var existingFlags string
-			if strings.HasPrefix(arg, "-ldflags=") {
-				existingFlags = strings.TrimPrefix(arg, "-ldflags=")
-			} else if i+1 < len(args) {
-				existingFlags = args[i+1]
-				i++ // Skip the next arg since we've consumed it
-			}
-			existingFlags = strings.TrimSpace(existingFlags)
-			if ldflags != "" {
-				ldflags += " " + existingFlags
-			}
-		} else {
-			result = append(result, arg)
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		value, ok := strings.CutPrefix(strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-"), "ldflags")
+		if !strings.HasPrefix(arg, "-") || !ok || (value != "" && value[0] != '=') {
+			rest = append(rest, arg)
+			continue
+		}
+		if value != "" {
+			value = value[1:]
+		} else if i+1 < len(args) {
+			value = args[i+1]
+			i++ // Skip the next arg since we've consumed it
 		}
+		userFlags, err := ParseLDFlags(stripPackagePattern(value))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid -ldflags %q: %v", value, err)
+		}
+		ldflags.Merge(userFlags)
+	}
+	return ldflags, rest, nil
+}
+
+// stripPackagePattern drops the pattern of a per-package flag value such as
+// "all=-s -w"; got's flags only take effect when linking the main package
+func stripPackagePattern(value string) string {
+	pattern, flags, ok := strings.Cut(value, "=")
+	if !ok || pattern == "" || strings.ContainsAny(pattern, " \t'\"") || strings.HasPrefix(pattern, "-") {
+		return value
+	}
+	return flags
+}
+
+// processArgsWithLDFlags merges got's linker flags for opts with the
+// -ldflags in args into a single -ldflags argument
+func processArgsWithLDFlags(args []string, opts LDFlagsOptions) ([]string, error) {
+	ldflags, rest, err := MergeLDFlags(args, opts)
+	if err != nil {
+		return nil, err
+	}
+	value, err := ldflags.Value()
+	if err != nil {
+		return nil, err
 	}
-	return append([]string{"-ldflags", ldflags}, result...)
+	return append([]string{"-ldflags", value}, rest...), nil
 }
 
 // GetGoCommandHelp returns the formatted help text for the specified go command
@@ -0,0 +1,59 @@
+package rungo
+
+import (
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gotray/got/internal/env"
+)
+
+// stampPackage is the package of the variables got stamps into executables
+const stampPackage = "github.com/gotray/got"
+
+// BuildStamps returns the variables of package got describing the Python
+// built against, which executables fall back to when env.txt is missing
+func BuildStamps(projectRoot string, layout env.PythonLayout) map[string]string {
+	stamps := map[string]string{"GotVersion": GotVersion()}
+	if info, err := layout.Info(); err == nil {
+		stamps["PythonVersion"] = info.Version
+		stamps["PythonVariant"] = info.Variant()
+		if abi := info.ABI(); abi != "" {
+			stamps["PythonABI"] = abi
+		}
+	}
+	if envs, err := env.ReadEnvFile(projectRoot); err == nil {
+		home := envs["PYTHONHOME"]
+		if home == "" {
+			home = layout.Home
+		}
+		if path := RelPythonPath(home, envs["PYTHONPATH"]); path != "" {
+			stamps["PythonPath"] = path
+		}
+	}
+	return stamps
+}
+
+// RelPythonPath returns the entries of pythonPath inside home relative to it,
+// with slashes and joined by ":" whatever the target OS. Entries outside
+// home don't move with the runtime and are left out.
+func RelPythonPath(home, pythonPath string) string {
+	var rel []string
+	for _, entry := range filepath.SplitList(pythonPath) {
+		r, err := filepath.Rel(home, entry)
+		if entry == "" || err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			continue
+		}
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	return strings.Join(rel, ":")
+}
+
+// GotVersion returns the module version of the running got, or "devel"
+// for builds from a source checkout
+func GotVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "devel"
+}
@@ -0,0 +1,29 @@
+package rungo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRelPythonPath(t *testing.T) {
+	home := filepath.Join(string(filepath.Separator), "proj", ".deps", "python")
+	join := func(paths ...string) string { return strings.Join(paths, string(os.PathListSeparator)) }
+	tests := []struct {
+		pythonPath string
+		want       string
+	}{
+		{"", ""},
+		{
+			join(filepath.Join(home, "lib", "python313.zip"), filepath.Join(home, "lib", "python3.13"), filepath.Join(home, "lib", "python3.13", "site-packages")),
+			"lib/python313.zip:lib/python3.13:lib/python3.13/site-packages",
+		},
+		{join(filepath.Join(home, "..", "src"), filepath.Join(home, "lib"), ""), "lib"},
+	}
+	for _, tt := range tests {
+		if got := RelPythonPath(home, tt.pythonPath); got != tt.want {
+			t.Errorf("RelPythonPath(%q) = %q, want %q", tt.pythonPath, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package trim
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// findModulesScript prints the modules imported at startup and by the
+// scripts in sys.argv as a JSON list
+const findModulesScript = `import sys
+startup = list(sys.modules)
+import json, modulefinder
+finder = modulefinder.ModuleFinder()
+for script in sys.argv[1:]:
+    finder.run_script(script)
+print(json.dumps(sorted(set(startup) | set(finder.modules))))
+`
+
+// FindModules runs the entry scripts through Python's modulefinder with the
+// interpreter python in the environment envs and returns the modules they
+// import, including those the interpreter imports at startup. Modules
+// imported dynamically, e.g. by importlib, aren't found.
+func FindModules(python string, envs map[string]string, entries []string) ([]string, error) {
+	cmd := exec.Command(python, append([]string{"-c", findModulesScript}, entries...)...)
+	cmd.Env = os.Environ()
+	for key, value := range envs {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("modulefinder failed: %v", err)
+	}
+	var modules []string
+	if err := json.Unmarshal(out, &modules); err != nil {
+		return nil, fmt.Errorf("failed to parse modulefinder output: %v", err)
+	}
+	return modules, nil
+}
+
+// ReadAllowlist reads module names from a file with one name per line,
+// ignoring blank lines and # comments
+func ReadAllowlist(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var modules []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			modules = append(modules, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return modules, nil
+}
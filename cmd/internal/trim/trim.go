@@ -0,0 +1,338 @@
+// Package trim copies a Python installation or got dist bundle, keeping only
+// the standard library and site-packages modules an application needs.
+package trim
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Options describes a trimmed copy
+type Options struct {
+	Src string // Python home or got dist bundle to trim
+	Dst string // directory of the trimmed copy, which must not exist
+	// Modules are the modules to keep, e.g. from FindModules. Top-level
+	// packages are kept whole. Nil keeps all modules except those in
+	// DefaultExcludes.
+	Modules []string
+	Zip     bool // zip the pure-Python standard library into python3x.zip
+}
+
+// Result reports the sizes of the trimmed tree
+type Result struct {
+	Before int64 // bytes in Src
+	After  int64 // bytes in Dst
+}
+
+// Saved returns the bytes trimming saved
+func (r *Result) Saved() int64 {
+	return r.Before - r.After
+}
+
+// DefaultExcludes are the standard library packages left out when no
+// modules are given: the test suite, IDLE, Tk and pip's bootstrapper
+var DefaultExcludes = []string{"test", "idlelib", "tkinter", "turtledemo", "ensurepip", "_tkinter"}
+
+// alwaysKeep are modules the interpreter or site imports on demand
+var alwaysKeep = []string{"encodings", "site", "sitecustomize", "usercustomize", "os", "_sysconfigdata"}
+
+// testDirs are directories of tests, which applications don't import
+var testDirs = map[string]bool{"__pycache__": true, "test": true, "tests": true, "idle_test": true}
+
+// Trim copies opts.Src to opts.Dst without the modules the application
+// doesn't need, caches, tests and static libraries
+func Trim(opts Options) (*Result, error) {
+	if _, err := os.Lstat(opts.Dst); err == nil {
+		return nil, fmt.Errorf("%s already exists", opts.Dst)
+	}
+	stdlib, err := StdlibDir(opts.Src)
+	if err != nil {
+		return nil, err
+	}
+	f := newFilter(opts.Modules)
+	err = filepath.Walk(opts.Src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(opts.Src, path)
+		if err != nil {
+			return err
+		}
+		if rel != "." && !f.keep(path, fi, stdlib) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return copyEntry(path, filepath.Join(opts.Dst, rel), fi)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy %s: %v", opts.Src, err)
+	}
+	if opts.Zip {
+		rel, _ := filepath.Rel(opts.Src, stdlib)
+		if err := zipStdlib(filepath.Join(opts.Dst, rel)); err != nil {
+			return nil, fmt.Errorf("failed to zip the standard library: %v", err)
+		}
+	}
+
+	result := &Result{}
+	if result.Before, err = dirSize(opts.Src); err != nil {
+		return nil, err
+	}
+	if result.After, err = dirSize(opts.Dst); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// StdlibDir returns the standard library directory of the Python home or
+// bundle root, lib/python3.x on Unix or Lib on Windows
+func StdlibDir(root string) (string, error) {
+	matches, _ := filepath.Glob(filepath.Join(root, "lib", "python3*", "os.py"))
+	if len(matches) == 0 {
+		matches, _ = filepath.Glob(filepath.Join(root, "Lib", "os.py"))
+	}
+	if len(matches) != 1 {
+		return "", fmt.Errorf("no Python standard library found in %s", root)
+	}
+	return filepath.Dir(matches[0]), nil
+}
+
+// filter decides which entries of the tree to keep
+type filter struct {
+	modules  map[string]bool // top-level modules to keep, nil to keep all but excludes
+	excludes map[string]bool
+}
+
+func newFilter(modules []string) *filter {
+	f := &filter{excludes: map[string]bool{}}
+	for _, name := range DefaultExcludes {
+		f.excludes[name] = true
+	}
+	if modules != nil {
+		f.modules = map[string]bool{}
+		for _, name := range append(modules, alwaysKeep...) {
+			top, _, _ := strings.Cut(name, ".")
+			f.modules[top] = true
+		}
+	}
+	return f
+}
+
+// keep reports whether to copy the entry at path of the tree with the
+// standard library in stdlib
+func (f *filter) keep(path string, fi os.FileInfo, stdlib string) bool {
+	name := fi.Name()
+	if fi.IsDir() && testDirs[name] {
+		return false
+	}
+	if !fi.IsDir() && (strings.HasSuffix(name, ".a") || strings.HasSuffix(name, ".pyc")) {
+		return false
+	}
+	parent := filepath.Dir(path)
+	switch {
+	case parent == stdlib:
+		if fi.IsDir() && strings.HasPrefix(name, "config-") {
+			return false
+		}
+		if fi.IsDir() && (name == "site-packages" || name == "lib-dynload") {
+			return true
+		}
+		return f.keepModule(name, fi)
+	case filepath.Base(parent) == "lib-dynload" || filepath.Base(parent) == "DLLs":
+		return f.keepModule(name, fi)
+	case filepath.Base(parent) == "site-packages":
+		if fi.IsDir() && (strings.HasSuffix(name, ".dist-info") || strings.HasSuffix(name, ".egg-info")) {
+			return true
+		}
+		return f.keepModule(name, fi)
+	}
+	return true
+}
+
+// keepModule reports whether to keep the module file or package directory
+// name; other files such as .pth files are kept
+func (f *filter) keepModule(name string, fi os.FileInfo) bool {
+	module := name
+	if !fi.IsDir() {
+		ext := filepath.Ext(name)
+		if ext != ".py" && ext != ".so" && ext != ".pyd" && ext != ".dylib" {
+			return true
+		}
+		// Extension modules are named like _ssl.cpython-313-x86_64-linux-gnu.so
+		module, _, _ = strings.Cut(name, ".")
+	}
+	if strings.HasPrefix(module, "_sysconfigdata") {
+		module = "_sysconfigdata"
+	}
+	if f.modules == nil {
+		return !f.excludes[module]
+	}
+	return f.modules[module]
+}
+
+// windowsDLLPattern matches the versioned Python DLL, not the stable ABI python3.dll
+var windowsDLLPattern = regexp.MustCompile(`^python(3\d+)t?\.dll$`)
+
+// stdlibZipPath returns where the interpreter looks for the zipped standard
+// library in stdlib: lib/python3x.zip on Unix, and python3x.zip next to the
+// Python DLL on Windows, in bin/ of a bundle or the root of a Python home
+func stdlibZipPath(stdlib string) (string, error) {
+	if filepath.Base(stdlib) != "Lib" {
+		version := strings.ReplaceAll(strings.TrimPrefix(filepath.Base(stdlib), "python"), ".", "")
+		return filepath.Join(filepath.Dir(stdlib), "python"+version+".zip"), nil
+	}
+	root := filepath.Dir(stdlib)
+	for _, dir := range []string{filepath.Join(root, "bin"), root} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if m := windowsDLLPattern.FindStringSubmatch(strings.ToLower(entry.Name())); m != nil {
+				return filepath.Join(dir, "python"+m[1]+".zip"), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no python3x.dll found next to %s to name the zip after", stdlib)
+}
+
+// zipStdlib moves the pure-Python modules of the standard library in stdlib
+// into python3x.zip, which the interpreter puts on sys.path ahead of the
+// directory. os.py stays as the landmark the interpreter finds its home by.
+func zipStdlib(stdlib string) error {
+	zipPath, err := stdlibZipPath(stdlib)
+	if err != nil {
+		return err
+	}
+	var files []string
+	err = filepath.Walk(stdlib, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() && (fi.Name() == "site-packages" || fi.Name() == "lib-dynload") {
+			return filepath.SkipDir
+		}
+		if fi.Mode().IsRegular() && strings.HasSuffix(path, ".py") && path != filepath.Join(stdlib, "os.py") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	out, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(out)
+	for _, path := range files {
+		rel, _ := filepath.Rel(stdlib, path)
+		if err := addZipFile(zw, path, filepath.ToSlash(rel)); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	for _, path := range files {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return removeEmptyDirs(stdlib)
+}
+
+func addZipFile(zw *zip.Writer, path, name string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, in)
+	return err
+}
+
+// removeEmptyDirs removes the empty directories below dir
+func removeEmptyDirs(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		sub := filepath.Join(dir, entry.Name())
+		if err := removeEmptyDirs(sub); err != nil {
+			return err
+		}
+		if rest, err := os.ReadDir(sub); err == nil && len(rest) == 0 {
+			if err := os.Remove(sub); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copyEntry copies a directory, file or symlink of the tree
+func copyEntry(src, dst string, fi os.FileInfo) error {
+	switch {
+	case fi.IsDir():
+		return os.MkdirAll(dst, fi.Mode().Perm()|0700)
+	case fi.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(link, dst)
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fi.Mode().Perm()|0200)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// dirSize returns the bytes of the regular files below dir
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.Mode().IsRegular() {
+			size += fi.Size()
+		}
+		return nil
+	})
+	return size, err
+}
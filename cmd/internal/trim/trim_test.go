@@ -0,0 +1,239 @@
+package trim
+
+import (
+	"archive/zip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var srcFiles = []string{
+	"got-bundle.json",
+	"bin/app",
+	"lib/libpython3.13.so.1.0",
+	"lib/libpython3.13.a",
+	"lib/python3.13/os.py",
+	"lib/python3.13/site.py",
+	"lib/python3.13/json/__init__.py",
+	"lib/python3.13/json/tests/test_json.py",
+	"lib/python3.13/json/__pycache__/__init__.cpython-313.pyc",
+	"lib/python3.13/email/__init__.py",
+	"lib/python3.13/encodings/utf_8.py",
+	"lib/python3.13/_sysconfigdata__linux_x86_64-linux-gnu.py",
+	"lib/python3.13/tkinter/__init__.py",
+	"lib/python3.13/test/test_os.py",
+	"lib/python3.13/config-3.13-x86_64-linux-gnu/Makefile",
+	"lib/python3.13/lib-dynload/_json.cpython-313-x86_64-linux-gnu.so",
+	"lib/python3.13/lib-dynload/_tkinter.cpython-313-x86_64-linux-gnu.so",
+	"lib/python3.13/site-packages/requests/__init__.py",
+	"lib/python3.13/site-packages/requests-2.32.0.dist-info/METADATA",
+	"lib/python3.13/site-packages/six.py",
+	"lib/python3.13/site-packages/extra.pth",
+}
+
+func writeTree(t *testing.T, root string, files []string) {
+	t.Helper()
+	for _, file := range files {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// listTree returns the files below root, with slashes
+func listTree(t *testing.T, root string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			rel, _ := filepath.Rel(root, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestTrim(t *testing.T) {
+	src := t.TempDir()
+	writeTree(t, src, srcFiles)
+	common := []string{
+		"bin/app",
+		"got-bundle.json",
+		"lib/libpython3.13.so.1.0",
+		"lib/python3.13/_sysconfigdata__linux_x86_64-linux-gnu.py",
+		"lib/python3.13/encodings/utf_8.py",
+		"lib/python3.13/json/__init__.py",
+		"lib/python3.13/lib-dynload/_json.cpython-313-x86_64-linux-gnu.so",
+		"lib/python3.13/os.py",
+		"lib/python3.13/site-packages/extra.pth",
+		"lib/python3.13/site-packages/requests-2.32.0.dist-info/METADATA",
+		"lib/python3.13/site-packages/requests/__init__.py",
+		"lib/python3.13/site.py",
+	}
+	tests := []struct {
+		name    string
+		modules []string
+		want    []string
+	}{
+		{
+			name:    "modules",
+			modules: []string{"json.decoder", "_json", "requests"},
+			want:    common,
+		},
+		{
+			name: "default excludes",
+			want: append([]string{"lib/python3.13/email/__init__.py", "lib/python3.13/site-packages/six.py"}, common...),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "out")
+			result, err := Trim(Options{Src: src, Dst: dst, Modules: tt.modules})
+			if err != nil {
+				t.Fatalf("Trim() error = %v", err)
+			}
+			want := append([]string{}, tt.want...)
+			sort.Strings(want)
+			if got := listTree(t, dst); !reflect.DeepEqual(got, want) {
+				t.Errorf("Trim() kept %q, want %q", got, want)
+			}
+			if result.Saved() <= 0 || result.After <= 0 {
+				t.Errorf("Trim() result = %+v, want savings", result)
+			}
+		})
+	}
+
+	if _, err := Trim(Options{Src: src, Dst: src}); err == nil {
+		t.Error("Trim() into an existing directory error = nil, want error")
+	}
+}
+
+func TestTrimZip(t *testing.T) {
+	src := t.TempDir()
+	writeTree(t, src, srcFiles)
+	dst := filepath.Join(t.TempDir(), "out")
+	if _, err := Trim(Options{Src: src, Dst: dst, Modules: []string{"json", "_json"}, Zip: true}); err != nil {
+		t.Fatalf("Trim() error = %v", err)
+	}
+
+	zr, err := zip.OpenReader(filepath.Join(dst, "lib", "python313.zip"))
+	if err != nil {
+		t.Fatalf("failed to open the zip: %v", err)
+	}
+	defer zr.Close()
+	var zipped []string
+	for _, f := range zr.File {
+		zipped = append(zipped, f.Name)
+	}
+	want := []string{"_sysconfigdata__linux_x86_64-linux-gnu.py", "encodings/utf_8.py", "json/__init__.py", "site.py"}
+	if !reflect.DeepEqual(zipped, want) {
+		t.Errorf("zip contains %q, want %q", zipped, want)
+	}
+	for _, file := range []string{"lib/python3.13/os.py", "lib/python3.13/lib-dynload/_json.cpython-313-x86_64-linux-gnu.so"} {
+		if _, err := os.Stat(filepath.Join(dst, file)); err != nil {
+			t.Errorf("%s was zipped or removed: %v", file, err)
+		}
+	}
+	for _, dir := range []string{"json", "encodings"} {
+		if _, err := os.Stat(filepath.Join(dst, "lib", "python3.13", dir)); !os.IsNotExist(err) {
+			t.Errorf("zipped package directory %s remains, Stat() error = %v", dir, err)
+		}
+	}
+}
+
+func TestTrimZipWindows(t *testing.T) {
+	src := t.TempDir()
+	writeTree(t, src, []string{
+		"got-bundle.json",
+		"bin/app.exe",
+		"bin/python3.dll",
+		"bin/python313.dll",
+		"Lib/os.py",
+		"Lib/encodings/utf_8.py",
+		"Lib/json/__init__.py",
+		"Lib/site-packages/six.py",
+		"DLLs/_json.pyd",
+	})
+	dst := filepath.Join(t.TempDir(), "out")
+	if _, err := Trim(Options{Src: src, Dst: dst, Zip: true}); err != nil {
+		t.Fatalf("Trim() error = %v", err)
+	}
+
+	// Windows only looks for the zip next to the Python DLL
+	zr, err := zip.OpenReader(filepath.Join(dst, "bin", "python313.zip"))
+	if err != nil {
+		t.Fatalf("failed to open the zip: %v", err)
+	}
+	defer zr.Close()
+	var zipped []string
+	for _, f := range zr.File {
+		zipped = append(zipped, f.Name)
+	}
+	if want := []string{"encodings/utf_8.py", "json/__init__.py"}; !reflect.DeepEqual(zipped, want) {
+		t.Errorf("zip contains %q, want %q", zipped, want)
+	}
+	for _, file := range []string{"Lib/os.py", "Lib/site-packages/six.py"} {
+		if _, err := os.Stat(filepath.Join(dst, file)); err != nil {
+			t.Errorf("%s was zipped or removed: %v", file, err)
+		}
+	}
+
+	// Without the DLL the zip can't be named after the version
+	noDLL := t.TempDir()
+	writeTree(t, noDLL, []string{"Lib/os.py", "Lib/json/__init__.py"})
+	if _, err := Trim(Options{Src: noDLL, Dst: filepath.Join(t.TempDir(), "out"), Zip: true}); err == nil {
+		t.Error("Trim() of a Lib layout without the Python DLL should fail with --zip")
+	}
+}
+
+func TestReadAllowlist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "allowlist.txt")
+	if err := os.WriteFile(path, []byte("# modules\njson\n\n  email.mime  # for mails\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadAllowlist(path)
+	if err != nil {
+		t.Fatalf("ReadAllowlist() error = %v", err)
+	}
+	if want := []string{"json", "email.mime"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadAllowlist() = %q, want %q", got, want)
+	}
+}
+
+func TestFindModules(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil || runtime.GOOS == "windows" {
+		t.Skip("python3 not found")
+	}
+	entry := filepath.Join(t.TempDir(), "main.py")
+	if err := os.WriteFile(entry, []byte("import json\nfrom email import message\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modules, err := FindModules(python, nil, []string{entry})
+	if err != nil {
+		t.Fatalf("FindModules() error = %v", err)
+	}
+	found := strings.Join(modules, " ")
+	for _, want := range []string{"json", "email.message", "encodings"} {
+		if !strings.Contains(" "+found+" ", " "+want+" ") {
+			t.Errorf("FindModules() = %s, missing %s", found, want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gotray/got/cmd/internal/binutil"
+	"github.com/spf13/cobra"
+)
+
+// patchRpathCmd represents the patch-rpath command
+var patchRpathCmd = &cobra.Command{
+	Use:   "patch-rpath [flags] <binary>...",
+	Short: "Rewrite the rpath and needed libraries of ELF binaries",
+	Long: `Patch-rpath sets the runtime library search path of already built ELF
+binaries, like patchelf but without the external tool. It makes binaries built
+by plain go build or third-party CI load libpython from a relocatable layout.
+
+The rpath replaces an existing DT_RUNPATH or DT_RPATH entry, or is added as
+DT_RUNPATH. --replace-needed OLD=NEW replaces a needed library, e.g. to load a
+differently named libpython.
+
+Example:
+  got patch-rpath --rpath '$ORIGIN/../lib' ./app
+  got patch-rpath --replace-needed libpython3.12.so.1.0=libpython3.12.so ./app`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rpath, _ := cmd.Flags().GetString("rpath")
+		replaceNeeded, _ := cmd.Flags().GetStringArray("replace-needed")
+
+		if err := patchRpath(args, rpath, replaceNeeded); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// patchRpath patches the binaries with rpath and the OLD=NEW replacements
+func patchRpath(binaries []string, rpath string, replaceNeeded []string) error {
+	opts := binutil.PatchOptions{Rpath: rpath, ReplaceNeeded: map[string]string{}}
+	for _, r := range replaceNeeded {
+		oldName, newName, ok := strings.Cut(r, "=")
+		if !ok || oldName == "" || newName == "" {
+			return fmt.Errorf("invalid --replace-needed %q, want OLD=NEW", r)
+		}
+		opts.ReplaceNeeded[oldName] = newName
+	}
+	if rpath == "" && len(opts.ReplaceNeeded) == 0 {
+		return fmt.Errorf("nothing to do, use --rpath or --replace-needed")
+	}
+	for _, binary := range binaries {
+		if err := binutil.PatchELF(binary, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(patchRpathCmd)
+
+	patchRpathCmd.Flags().String("rpath", "", "Runtime library search path to set, e.g. '$ORIGIN/../lib'")
+	patchRpathCmd.Flags().StringArray("replace-needed", nil, "Replace a needed library, as OLD=NEW")
+}
@@ -0,0 +1,33 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"os"
+
+	"github.com/gotray/got/cmd/internal/pkgconfig"
+	"github.com/spf13/cobra"
+)
+
+// pkgConfigCmd represents the pkg-config command
+var pkgConfigCmd = &cobra.Command{
+	Use:   "pkg-config [flags] [--] <package>...",
+	Short: "Resolve compiler and linker flags from pkg-config files",
+	Long: `Pkg-config reads .pc files like pkg-config, searching PKG_CONFIG_PATH and
+PKG_CONFIG_LIBDIR and honoring PKG_CONFIG_SYSROOT_DIR. Got points cgo at it
+through PKG_CONFIG, so no separate pkg-config needs to be installed.
+
+Example:
+  got pkg-config --cflags python3-embed
+  got pkg-config --libs --static python3-embed
+  got pkg-config --modversion python3`,
+	DisableFlagParsing: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(pkgconfig.Main(args, os.Stdout, os.Stderr))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pkgConfigCmd)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gotray/got/cmd/internal/install"
+	"github.com/gotray/got/cmd/internal/rungo"
+	"github.com/spf13/cobra"
+)
+
+// restorePythonCmd represents the restore-python command
+var restorePythonCmd = &cobra.Command{
+	Use:   "restore-python",
+	Short: "Install the parts of the project's Python left out by its install profile",
+	Long: `Restore-python downloads the project's Python again and installs the files
+got init --python-profile minimal or standard left out, such as the test suite
+or tkinter, making the installation full.
+
+Example:
+  got init --python-profile minimal my-project
+  cd my-project
+  got restore-python`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+
+		wd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to get working directory: %v\n", err)
+			os.Exit(1)
+		}
+		projectRoot, err := rungo.FindProjectRoot(wd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: should run this command in a Got project: %v\n", err)
+			os.Exit(1)
+		}
+		if err := install.RestorePython(projectRoot, verbose); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restorePythonCmd)
+	restorePythonCmd.Flags().BoolP("verbose", "v", false, "Enable verbose output")
+}
@@ -5,6 +5,10 @@ package cmd
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gotray/got/cmd/internal/pkgconfig"
 
 	"github.com/spf13/cobra"
 )
@@ -22,6 +26,8 @@ It provides commands to:
 - Run Go applications with Python runtime support
 - Install Go packages with Python dependencies
 - Add or remove Python packages to/from your project
+- Diagnose problems with the Go, Python and C toolchain setup
+- Bundle applications with the Python runtime for distribution
 
 Use "got help [command]" for more information about a command.`,
 	// Uncomment the following line if your bare application
@@ -32,6 +38,10 @@ Use "got help [command]" for more information about a command.`,
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	// Invoked through the project's pkg-config link by cgo
+	if name := strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe"); name == "pkg-config" {
+		os.Exit(pkgconfig.Main(os.Args[1:], os.Stdout, os.Stderr))
+	}
 	err := rootCmd.Execute()
 	if err != nil {
 		os.Exit(1)
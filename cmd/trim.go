@@ -0,0 +1,139 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gotray/got/cmd/internal/rungo"
+	"github.com/gotray/got/cmd/internal/trim"
+	"github.com/gotray/got/internal/env"
+	"github.com/spf13/cobra"
+)
+
+// trimCmd represents the trim command
+var trimCmd = &cobra.Command{
+	Use:   "trim [flags] [dir]",
+	Short: "Copy the Python runtime without the modules the application doesn't use",
+	Long: `Trim copies a got dist bundle or Python home, by default the project's Python,
+keeping only the standard library and site-packages modules the application
+needs:
+
+  --entry <script>     find the modules a Python entry script imports with
+                       modulefinder, may be repeated
+  --keep <module>      keep a module, e.g. one imported dynamically
+  --allowlist <file>   keep the modules listed in a file, one per line
+
+Without any of them all modules are kept except the test suite, idlelib,
+tkinter, turtledemo and ensurepip. __pycache__ and test directories and static
+libraries are always left out. --zip moves the pure-Python standard library
+into lib/python3x.zip, or python3x.zip next to the Python DLL on Windows.
+
+Example:
+  got dist -o dist .
+  got trim -o dist-trimmed --entry app/main.py --zip dist`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		entries, _ := cmd.Flags().GetStringArray("entry")
+		keep, _ := cmd.Flags().GetStringArray("keep")
+		allowlist, _ := cmd.Flags().GetString("allowlist")
+		zip, _ := cmd.Flags().GetBool("zip")
+
+		src := ""
+		if len(args) > 0 {
+			src = args[0]
+		}
+		if err := trimRuntime(src, output, entries, keep, allowlist, zip); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// trimRuntime copies src to output with the modules selected by entries,
+// keep and allowlist
+func trimRuntime(src, output string, entries, keep []string, allowlist string, zip bool) error {
+	var projectRoot string
+	if src == "" || len(entries) > 0 {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get working directory: %v", err)
+		}
+		if projectRoot, err = rungo.FindProjectRoot(wd); err != nil {
+			return fmt.Errorf("should run this command in a Got project: %v", err)
+		}
+	}
+	if src == "" {
+		src = env.GetPythonLayout(projectRoot).Home
+	}
+	if output == "" {
+		return fmt.Errorf("missing output directory, use -o")
+	}
+
+	var modules []string
+	if len(entries) > 0 {
+		python, err := env.GetPythonLayout(projectRoot).PythonEnv().Python()
+		if err != nil {
+			return err
+		}
+		envs, err := env.ReadEnv(projectRoot)
+		if err != nil {
+			return err
+		}
+		found, err := trim.FindModules(python, envs, entries)
+		if err != nil {
+			return err
+		}
+		modules = append(modules, found...)
+	}
+	if allowlist != "" {
+		listed, err := trim.ReadAllowlist(allowlist)
+		if err != nil {
+			return err
+		}
+		modules = append(modules, listed...)
+	}
+	modules = append(modules, keep...)
+	if len(entries) == 0 && allowlist == "" && len(keep) == 0 {
+		modules = nil
+	}
+
+	result, err := trim.Trim(trim.Options{Src: src, Dst: output, Modules: modules, Zip: zip})
+	if err != nil {
+		return err
+	}
+	percent := 0.0
+	if result.Before > 0 {
+		percent = float64(result.Saved()) * 100 / float64(result.Before)
+	}
+	fmt.Printf("Trimmed %s to %s in %s, saved %s (%.0f%%)\n",
+		formatBytes(result.Before), formatBytes(result.After), output, formatBytes(result.Saved()), percent)
+	return nil
+}
+
+// formatBytes formats a size in bytes for humans
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(trimCmd)
+
+	trimCmd.Flags().StringP("output", "o", "", "Directory of the trimmed copy")
+	trimCmd.Flags().StringArray("entry", nil, "Python entry script to find imported modules from")
+	trimCmd.Flags().StringArray("keep", nil, "Module to keep")
+	trimCmd.Flags().String("allowlist", "", "File listing the modules to keep")
+	trimCmd.Flags().Bool("zip", false, "Zip the pure-Python standard library")
+}
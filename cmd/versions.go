@@ -0,0 +1,82 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gotray/got/cmd/internal/install"
+	"github.com/spf13/cobra"
+)
+
+// versionsCmd represents the versions command
+var versionsCmd = &cobra.Command{
+	Use:   "versions [python|go]",
+	Short: "List Python and Go versions available for installation",
+	Long: `List the Python versions published by python-build-standalone and the Go
+versions published on go.dev. Release listings are cached in ~/.got/cache so
+that version resolution keeps working offline.
+
+Example:
+  got versions
+  got versions python
+  got versions go --limit 5
+  got versions --refresh`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{"python", "go"},
+	Run: func(cmd *cobra.Command, args []string) {
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		which := ""
+		if len(args) > 0 {
+			which = args[0]
+		}
+		if which != "" && which != "python" && which != "go" {
+			fmt.Fprintf(os.Stderr, "Error: unknown component %q, expected python or go\n", which)
+			os.Exit(1)
+		}
+
+		if which == "" || which == "python" {
+			releases, err := install.PythonReleases(refresh)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(bold("Python versions (version: build dates)"))
+			for i, v := range install.PythonVersions(releases) {
+				if limit > 0 && i >= limit {
+					break
+				}
+				fmt.Printf("  %-8s %s\n", v.Version, strings.Join(v.BuildDates, " "))
+			}
+		}
+
+		if which == "" || which == "go" {
+			releases, err := install.GoReleases(refresh)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				os.Exit(1)
+			}
+			if which == "" {
+				fmt.Println()
+			}
+			fmt.Println(bold("Go versions"))
+			for i, v := range install.GoVersions(releases) {
+				if limit > 0 && i >= limit {
+					break
+				}
+				fmt.Printf("  %s\n", v)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionsCmd)
+	versionsCmd.Flags().Bool("refresh", false, "Refresh the cached release listings")
+	versionsCmd.Flags().Int("limit", 20, "Maximum number of versions to list per component (0 for all)")
+}
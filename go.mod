@@ -7,14 +7,15 @@ toolchain go1.23.3
 require (
 	github.com/fatih/color v1.18.0
 	github.com/klauspost/compress v1.17.11
+	github.com/mattn/go-isatty v0.0.20
 	github.com/spf13/cobra v1.8.1
+	github.com/ulikunitz/xz v0.5.12
 	go.uber.org/zap v1.27.0
 )
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
-	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	go.uber.org/multierr v1.10.0 // indirect
 	golang.org/x/sys v0.25.0 // indirect
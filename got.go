@@ -1,26 +1,391 @@
 package got
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 
 	"github.com/gotray/got/internal/env"
 )
 
 var (
 	ProjectRoot string
+	// PythonLink is "static" when got build linked libpython into the
+	// executable, which then needs PYTHONHOME to point at a bundled standard
+	// library rather than at the project's Python
+	PythonLink string
+	// BundleRoot is the root of the got dist bundle relative to the directory
+	// of the executable, set for executables built by got dist
+	BundleRoot string
+
+	// Set by got build from the Python built against, see BuildInfo
+	PythonVersion string
+	PythonABI     string
+	PythonVariant string
+	PythonPath    string // entries relative to PYTHONHOME, slash-separated and joined by ":"
+	GotVersion    string
 )
 
-func SetEnv() {
+// BuildMetadata describes the Python runtime an executable was built against
+type BuildMetadata struct {
+	PythonVersion string   // e.g. "3.13.1"
+	PythonABI     string   // ABI flags, e.g. "t" for free-threaded builds
+	PythonVariant string   // e.g. "freethreaded+pgo"
+	PythonLink    string   // "static" if libpython is linked into the executable
+	PythonPath    []string // PYTHONPATH entries relative to PYTHONHOME
+	GotVersion    string   // version of got that built the executable
+}
+
+// BuildInfo returns the metadata got build stamped into the executable.
+// Its fields are empty for executables built without got.
+func BuildInfo() BuildMetadata {
+	m := BuildMetadata{
+		PythonVersion: PythonVersion,
+		PythonABI:     PythonABI,
+		PythonVariant: PythonVariant,
+		PythonLink:    PythonLink,
+		GotVersion:    GotVersion,
+	}
+	if PythonPath != "" {
+		for _, entry := range strings.Split(PythonPath, ":") {
+			m.PythonPath = append(m.PythonPath, filepath.FromSlash(entry))
+		}
+	}
+	return m
+}
+
+// stampedEnv returns the environment for the runtime in home from the
+// stamped metadata, or false if the executable has none
+func stampedEnv(home string) (map[string]string, bool) {
+	m := BuildInfo()
+	if m.PythonVersion == "" && len(m.PythonPath) == 0 {
+		return nil, false
+	}
+	paths := make([]string, len(m.PythonPath))
+	for i, entry := range m.PythonPath {
+		paths[i] = filepath.Join(home, entry)
+	}
+	return env.GeneratePythonEnv(home, strings.Join(paths, string(os.PathListSeparator))), true
+}
+
+// ProjectRootEnv names the variable overriding the stamped ProjectRoot
+const ProjectRootEnv = "GOT_PROJECT_ROOT"
+
+// defaultBundleRoot is the bundle root relative to the directory of
+// executables not built by got dist
+const defaultBundleRoot = ".."
+
+// Sources of the Python runtime, in the order Resolve tries them
+const (
+	SourceOverride    = "override"     // the project in GOT_PROJECT_ROOT
+	SourceBundle      = "bundle"       // the got dist bundle containing the executable
+	SourceProjectRoot = "project-root" // the project stamped in ProjectRoot
+)
+
+// Resolution describes where the Python runtime was found
+type Resolution struct {
+	Source string            // one of the Source constants
+	Root   string            // project or bundle root directory
+	Env    map[string]string // environment variables for the runtime
+}
+
+func (r *Resolution) String() string {
+	return r.Source + " " + r.Root
+}
+
+// Resolve finds the Python runtime: the project named by GOT_PROJECT_ROOT,
+// then a got dist bundle containing the executable, then the project
+// stamped in ProjectRoot
+func Resolve() (*Resolution, error) {
+	if root := os.Getenv(ProjectRootEnv); root != "" {
+		envs, err := projectEnv(root)
+		if err != nil {
+			return nil, fmt.Errorf("%s=%s: %v", ProjectRootEnv, root, err)
+		}
+		return &Resolution{Source: SourceOverride, Root: root, Env: envs}, nil
+	}
+	if home := bundleHome(); home != "" {
+		envs, ok := stampedEnv(home)
+		if !ok {
+			envs = map[string]string{"PYTHONHOME": home}
+		}
+		return &Resolution{Source: SourceBundle, Root: home, Env: envs}, nil
+	}
 	if ProjectRoot == "" {
-		fmt.Fprintf(os.Stderr, "WARNING: github.com/gotray/got.ProjectRoot is not set, compile with got to set it\n")
-		return
+		return nil, fmt.Errorf("github.com/gotray/got.ProjectRoot is not set, compile with got or set %s", ProjectRootEnv)
+	}
+	envs, err := projectEnv(ProjectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("%v, set %s if the project moved", err, ProjectRootEnv)
+	}
+	return &Resolution{Source: SourceProjectRoot, Root: ProjectRoot, Env: envs}, nil
+}
+
+// projectEnv returns the environment for the Python of the project in root,
+// from its env.txt or, if that is missing, the stamped metadata
+func projectEnv(root string) (map[string]string, error) {
+	envs, err := env.ReadEnv(root)
+	if err == nil {
+		return envs, nil
 	}
-	envs, err := env.ReadEnv(ProjectRoot)
+	home := env.GetPythonLayout(root).Home
+	if _, statErr := os.Stat(home); statErr == nil {
+		if envs, ok := stampedEnv(home); ok {
+			return envs, nil
+		}
+	}
+	return nil, err
+}
+
+// Option configures Setup
+type Option func(*setupOptions)
+
+type setupOptions struct {
+	pythonPath []string
+	noUserSite bool
+	safePath   bool
+	dryRun     bool
+	noABICheck bool
+}
+
+// WithPythonPath adds entries to PYTHONPATH ahead of the runtime's own
+func WithPythonPath(paths ...string) Option {
+	return func(o *setupOptions) { o.pythonPath = append(o.pythonPath, paths...) }
+}
+
+// WithNoUserSite sets PYTHONNOUSERSITE so that the user site-packages
+// directory isn't added to sys.path
+func WithNoUserSite() Option {
+	return func(o *setupOptions) { o.noUserSite = true }
+}
+
+// WithSafePath sets PYTHONSAFEPATH so that the directory of the script or
+// the working directory isn't prepended to sys.path
+func WithSafePath() Option {
+	return func(o *setupOptions) { o.safePath = true }
+}
+
+// WithIsolation combines WithNoUserSite and WithSafePath
+func WithIsolation() Option {
+	return func(o *setupOptions) { o.noUserSite, o.safePath = true, true }
+}
+
+// WithDryRun makes Setup return the environment without applying it
+func WithDryRun() Option {
+	return func(o *setupOptions) { o.dryRun = true }
+}
+
+// WithoutABICheck makes Setup skip comparing the Python found with the one
+// the executable was built against, e.g. when only running subprocesses
+func WithoutABICheck() Option {
+	return func(o *setupOptions) { o.noABICheck = true }
+}
+
+// Runtime is the Python runtime configured by Setup
+type Runtime struct {
+	Resolution
+	// Applied reports whether Env was set in the process environment
+	Applied bool
+}
+
+// Setup finds the Python runtime with Resolve, adjusts its environment by
+// opts and sets it in the process environment unless WithDryRun is given
+func Setup(opts ...Option) (*Runtime, error) {
+	var o setupOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	r, err := Resolve()
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	if !o.noABICheck {
+		if err := checkABI(r); err != nil {
+			return nil, err
+		}
+	}
+	rt := &Runtime{Resolution: *r}
+	if len(o.pythonPath) > 0 {
+		paths := o.pythonPath
+		if existing := rt.Env["PYTHONPATH"]; existing != "" {
+			paths = append(append([]string{}, paths...), existing)
+		}
+		rt.Env["PYTHONPATH"] = strings.Join(paths, string(os.PathListSeparator))
+	}
+	if o.noUserSite {
+		rt.Env["PYTHONNOUSERSITE"] = "1"
+	}
+	if o.safePath {
+		rt.Env["PYTHONSAFEPATH"] = "1"
+	}
+	if o.dryRun {
+		return rt, nil
+	}
+	for key, value := range rt.Env {
+		if err := os.Setenv(key, value); err != nil {
+			return nil, fmt.Errorf("failed to set %s: %v", key, err)
+		}
+	}
+	rt.Applied = true
+	if os.Getenv("GOT_DEBUG") != "" {
+		fmt.Fprintf(os.Stderr, "got: using Python runtime from %s\n", r)
+	}
+	return rt, nil
+}
+
+// Environ returns the environment of the process with the runtime's
+// variables applied, for running subprocesses
+func (rt *Runtime) Environ() []string {
+	environ := make([]string, 0, len(rt.Env))
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		if _, ok := lookupEnv(rt.Env, key); !ok {
+			environ = append(environ, kv)
+		}
+	}
+	for key, value := range rt.Env {
+		environ = append(environ, key+"="+value)
+	}
+	return environ
+}
+
+// PythonCommand returns a command running the runtime's interpreter with args
+func (rt *Runtime) PythonCommand(args ...string) *exec.Cmd {
+	python, err := rt.layout().PythonEnv().Python()
+	if err != nil {
+		return failedCommand("python", args, err)
+	}
+	cmd := exec.Command(python, args...)
+	cmd.Env = rt.Environ()
+	return cmd
+}
+
+// Command returns a command running name with args. Names without a path
+// separator are looked up in the runtime's script directories first, so that
+// console scripts installed by pip take precedence over those in PATH.
+func (rt *Runtime) Command(name string, args ...string) *exec.Cmd {
+	path := name
+	if !strings.ContainsRune(name, filepath.Separator) && !strings.ContainsRune(name, '/') {
+		if script := rt.findScript(name); script != "" {
+			path = script
+		}
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Env = rt.Environ()
+	return cmd
+}
+
+// findScript returns the path of the executable name in the runtime's bin
+// (or Scripts on Windows) directories, or an empty string if there is none
+func (rt *Runtime) findScript(name string) string {
+	layout := rt.layout()
+	dirs := []string{layout.BinDir()}
+	names := []string{name}
+	if runtime.GOOS == "windows" {
+		dirs = append(dirs, filepath.Join(layout.Home, "Scripts"))
+		if filepath.Ext(name) == "" {
+			names = append([]string{name + ".exe", name + ".cmd", name + ".bat"}, names...)
+		}
+	}
+	for _, dir := range dirs {
+		for _, name := range names {
+			path := filepath.Join(dir, name)
+			if fi, err := os.Stat(path); err == nil && !fi.IsDir() {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// layout returns the Python installation of the runtime
+func (r *Resolution) layout() env.PythonLayout {
+	if r.Source == SourceBundle {
+		return env.PythonLayout{Home: r.Root, LibDir: filepath.Join(r.Root, "lib")}
+	}
+	return env.GetPythonLayout(r.Root)
+}
+
+// PythonCommand returns a command running the project's interpreter with
+// args in the environment of the runtime found by Resolve. The parent
+// process environment is left unchanged.
+func PythonCommand(args ...string) *exec.Cmd {
+	rt, err := Setup(WithDryRun(), WithoutABICheck())
+	if err != nil {
+		return failedCommand("python", args, err)
+	}
+	return rt.PythonCommand(args...)
+}
+
+// Command returns a command running name with args like Runtime.Command in
+// the environment of the runtime found by Resolve. The parent process
+// environment is left unchanged.
+func Command(name string, args ...string) *exec.Cmd {
+	rt, err := Setup(WithDryRun(), WithoutABICheck())
+	if err != nil {
+		return failedCommand(name, args, err)
+	}
+	return rt.Command(name, args...)
+}
+
+// failedCommand returns a command whose Start, Run and Output report err,
+// like exec.Command does for executables it can't find
+func failedCommand(name string, args []string, err error) *exec.Cmd {
+	return &exec.Cmd{Path: name, Args: append([]string{name}, args...), Err: err}
+}
+
+// lookupEnv looks up key in envs, ignoring case on Windows
+func lookupEnv(envs map[string]string, key string) (string, bool) {
+	if runtime.GOOS != "windows" {
+		value, ok := envs[key]
+		return value, ok
+	}
+	for k, v := range envs {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// SetEnv sets up the Python runtime like Setup without options, warning
+// instead of failing if there is none. It exits if the runtime doesn't match
+// the one the executable was built against, which would crash the embedded
+// interpreter. Use Resolve or Setup to find out where the runtime was found.
+func SetEnv() {
+	_, err := Setup()
+	var mismatch *ABIMismatchError
+	if errors.As(err, &mismatch) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: no Python runtime found: %v\n", err)
+	}
+}
+
+// bundleHome returns the root of the got dist bundle containing the running
+// executable, or an empty string if it isn't in one
+func bundleHome() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	rel := BundleRoot
+	if rel == "" {
+		// Executables built by got build may be copied into a bundle as well
+		rel = defaultBundleRoot
 	}
-	for key, value := range envs {
-		os.Setenv(key, value)
+	home := filepath.Join(filepath.Dir(exe), rel)
+	if _, err := os.Stat(filepath.Join(home, env.BundleMarkerFile)); err != nil {
+		return ""
 	}
+	return home
 }
@@ -0,0 +1,265 @@
+package got
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gotray/got/internal/env"
+)
+
+// SetEnv keeps the signature existing callers use as a func() value
+var _ func() = SetEnv
+
+func TestResolve(t *testing.T) {
+	project := t.TempDir()
+	if err := os.MkdirAll(env.GetDepsDir(project), 0755); err != nil {
+		t.Fatal(err)
+	}
+	pythonHome := filepath.Join(project, ".deps", "python")
+	if err := env.WriteEnvFile(project, pythonHome, "/site-packages"); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(t.TempDir(), "moved")
+
+	tests := []struct {
+		name        string
+		override    string
+		projectRoot string
+		wantSource  string
+		wantErr     string
+	}{
+		{name: "override", override: project, projectRoot: missing, wantSource: SourceOverride},
+		{name: "override missing", override: missing, projectRoot: project, wantErr: ProjectRootEnv},
+		{name: "project root", projectRoot: project, wantSource: SourceProjectRoot},
+		{name: "project root moved", projectRoot: missing, wantErr: ProjectRootEnv},
+		{name: "project root unset", wantErr: "ProjectRoot is not set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ProjectRootEnv, tt.override)
+			old := ProjectRoot
+			ProjectRoot = tt.projectRoot
+			defer func() { ProjectRoot = old }()
+
+			got, err := Resolve()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Resolve() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if got.Source != tt.wantSource || got.Root != project {
+				t.Errorf("Resolve() = %v, want %s %s", got, tt.wantSource, project)
+			}
+			if got.Env["PYTHONHOME"] != pythonHome {
+				t.Errorf("PYTHONHOME = %q, want %q", got.Env["PYTHONHOME"], pythonHome)
+			}
+		})
+	}
+}
+
+func TestSetup(t *testing.T) {
+	project := t.TempDir()
+	if err := os.MkdirAll(env.GetDepsDir(project), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.WriteEnvFile(project, filepath.Join(project, ".deps", "python"), "/site-packages"); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ProjectRootEnv, project)
+	sep := string(os.PathListSeparator)
+
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]string
+	}{
+		{
+			name: "defaults",
+			want: map[string]string{"PYTHONPATH": "/site-packages", "PYTHONNOUSERSITE": "", "PYTHONSAFEPATH": ""},
+		},
+		{
+			name: "python path",
+			opts: []Option{WithPythonPath("/a", "/b")},
+			want: map[string]string{"PYTHONPATH": "/a" + sep + "/b" + sep + "/site-packages"},
+		},
+		{
+			name: "isolation",
+			opts: []Option{WithIsolation()},
+			want: map[string]string{"PYTHONNOUSERSITE": "1", "PYTHONSAFEPATH": "1"},
+		},
+		{
+			name: "no user site",
+			opts: []Option{WithNoUserSite()},
+			want: map[string]string{"PYTHONNOUSERSITE": "1", "PYTHONSAFEPATH": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PYTHONPATH", "unchanged")
+			rt, err := Setup(append(tt.opts, WithDryRun())...)
+			if err != nil {
+				t.Fatalf("Setup() error = %v", err)
+			}
+			if rt.Applied || os.Getenv("PYTHONPATH") != "unchanged" {
+				t.Errorf("Setup() with WithDryRun applied the environment")
+			}
+			for key, want := range tt.want {
+				if got := rt.Env[key]; got != want {
+					t.Errorf("Env[%s] = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("apply", func(t *testing.T) {
+		// Restore the variables Setup sets after the test
+		for _, key := range []string{"PYTHONHOME", "PYTHONPATH", "PATH", "PYTHONSAFEPATH"} {
+			t.Setenv(key, os.Getenv(key))
+		}
+		rt, err := Setup(WithSafePath())
+		if err != nil {
+			t.Fatalf("Setup() error = %v", err)
+		}
+		if !rt.Applied || os.Getenv("PYTHONPATH") != "/site-packages" || os.Getenv("PYTHONSAFEPATH") != "1" {
+			t.Errorf("Setup() didn't apply the environment")
+		}
+	})
+}
+
+func TestCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test scripts are shell scripts")
+	}
+	project := t.TempDir()
+	pythonHome := filepath.Join(project, ".deps", "python")
+	binDir := filepath.Join(pythonHome, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\necho \"$PYTHONHOME\" \"$@\"\n"
+	for _, name := range []string{"python3", "alembic"} {
+		if err := os.WriteFile(filepath.Join(binDir, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := env.WriteEnvFile(project, pythonHome, "/site-packages"); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ProjectRootEnv, project)
+	t.Setenv("PYTHONHOME", "parent")
+
+	tests := []struct {
+		name     string
+		cmd      *exec.Cmd
+		wantPath string
+	}{
+		{name: "python", cmd: PythonCommand("-V"), wantPath: filepath.Join(binDir, "python3")},
+		{name: "console script", cmd: Command("alembic", "-V"), wantPath: filepath.Join(binDir, "alembic")},
+		{name: "path", cmd: Command(filepath.Join(binDir, "alembic"), "-V"), wantPath: filepath.Join(binDir, "alembic")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.cmd.Path != tt.wantPath {
+				t.Errorf("Path = %q, want %q", tt.cmd.Path, tt.wantPath)
+			}
+			out, err := tt.cmd.Output()
+			if err != nil {
+				t.Fatalf("Output() error = %v", err)
+			}
+			if got, want := strings.TrimSpace(string(out)), pythonHome+" -V"; got != want {
+				t.Errorf("Output() = %q, want %q", got, want)
+			}
+		})
+	}
+	if got := os.Getenv("PYTHONHOME"); got != "parent" {
+		t.Errorf("PYTHONHOME of the parent = %q, want unchanged", got)
+	}
+
+	t.Setenv(ProjectRootEnv, filepath.Join(project, "missing"))
+	if err := PythonCommand("-V").Run(); err == nil || !strings.Contains(err.Error(), ProjectRootEnv) {
+		t.Errorf("Run() error = %v, want the resolve error", err)
+	}
+}
+
+func TestStampedFallback(t *testing.T) {
+	project := t.TempDir()
+	pythonHome := filepath.Join(project, ".deps", "python")
+	if err := os.MkdirAll(pythonHome, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ProjectRootEnv, project)
+	defer func(version, path string) { PythonVersion, PythonPath = version, path }(PythonVersion, PythonPath)
+
+	PythonVersion, PythonPath = "", ""
+	if _, err := Resolve(); err == nil {
+		t.Fatal("Resolve() without env.txt or stamps error = nil, want error")
+	}
+
+	PythonVersion, PythonPath = "3.13.1", "lib/python3.13:lib/python3.13/site-packages"
+	r, err := Resolve()
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	sep := string(os.PathListSeparator)
+	want := filepath.Join(pythonHome, "lib", "python3.13") + sep + filepath.Join(pythonHome, "lib", "python3.13", "site-packages")
+	if r.Env["PYTHONHOME"] != pythonHome || r.Env["PYTHONPATH"] != want {
+		t.Errorf("Resolve() env = %v, want PYTHONHOME %s and PYTHONPATH %s", r.Env, pythonHome, want)
+	}
+	if got := BuildInfo(); got.PythonVersion != "3.13.1" || len(got.PythonPath) != 2 {
+		t.Errorf("BuildInfo() = %+v", got)
+	}
+}
+
+func TestCheckABI(t *testing.T) {
+	newHome := func(stdlibs ...string) string {
+		home := t.TempDir()
+		for _, stdlib := range stdlibs {
+			dir := filepath.Join(home, "lib", stdlib)
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "os.py"), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return home
+	}
+	tests := []struct {
+		name    string
+		version string
+		abi     string
+		home    string
+		wantErr bool
+	}{
+		{name: "not stamped", home: newHome("python3.12")},
+		{name: "same", version: "3.13.1", home: newHome("python3.13")},
+		{name: "several versions", version: "3.13.1", home: newHome("python3.12", "python3.13")},
+		{name: "free-threaded", version: "3.13.1", abi: "t", home: newHome("python3.13t")},
+		{name: "other version", version: "3.13.1", home: newHome("python3.12"), wantErr: true},
+		{name: "other ABI", version: "3.13.1", abi: "t", home: newHome("python3.13"), wantErr: true},
+		{name: "unknown layout", version: "3.13.1", home: newHome()},
+	}
+	defer func(version, abi string) { PythonVersion, PythonABI = version, abi }(PythonVersion, PythonABI)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if runtime.GOOS == "windows" {
+				t.Skip("Windows installations don't name the standard library after the version")
+			}
+			PythonVersion, PythonABI = tt.version, tt.abi
+			err := checkABI(&Resolution{Env: map[string]string{"PYTHONHOME": tt.home}})
+			var mismatch *ABIMismatchError
+			if got := errors.As(err, &mismatch); got != tt.wantErr {
+				t.Errorf("checkABI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// BundleMarkerFile marks the root directory of a got dist bundle
+const BundleMarkerFile = "got-bundle.json"
+
+// BundleInfo describes the Python runtime of a got dist bundle
+type BundleInfo struct {
+	PythonVersion string `json:"python_version"`
+	PythonLink    string `json:"python_link,omitempty"`
+}
+
+// WriteBundleInfo writes the marker of the bundle at root
+func WriteBundleInfo(root string, info *BundleInfo) error {
+	content, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(root, BundleMarkerFile)
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return nil
+}
+
+// ReadBundleInfo reads the marker of the bundle at root
+func ReadBundleInfo(root string) (*BundleInfo, error) {
+	path := filepath.Join(root, BundleMarkerFile)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info := &BundleInfo{}
+	if err := json.Unmarshal(content, info); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return info, nil
+}
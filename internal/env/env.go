@@ -19,7 +19,8 @@ const (
 	mingwDir  = "mingw"
 	mingwRoot = mingwDir + "/mingw64"
 
-	tinyPkgConfigDir = "tiny-pkg-config"
+	// zigDir is the directory name for the zig C toolchain
+	zigDir = "zig"
 )
 
 func GetDepsDir(projectPath string) string {
@@ -37,10 +38,7 @@ func GetPythonRoot(projectPath string) string {
 
 // GetPythonBinDir returns the Python binary directory path relative to project path
 func GetPythonBinDir(projectPath string) string {
-	if runtime.GOOS == "windows" {
-		return filepath.Join(GetPythonRoot(projectPath))
-	}
-	return filepath.Join(GetPythonRoot(projectPath), "bin")
+	return pythonBinDir(GetPythonRoot(projectPath))
 }
 
 // GetPythonLibDir returns the Python library directory path relative to project path
@@ -81,32 +79,78 @@ func GetMingwRoot(projectPath string) string {
 	return filepath.Join(projectPath, depsDir, mingwRoot)
 }
 
-func GetTinyPkgConfigDir(projectPath string) string {
-	return filepath.Join(projectPath, depsDir, tinyPkgConfigDir)
+// GetZigDir returns the zig C toolchain directory path relative to project path
+func GetZigDir(projectPath string) string {
+	return filepath.Join(projectPath, depsDir, zigDir)
+}
+
+// GetZigCC returns the C and C++ compiler wrappers of the zig toolchain
+func GetZigCC(projectPath string) (cc, cxx string) {
+	binDir := filepath.Join(GetZigDir(projectPath), "bin")
+	return filepath.Join(binDir, "cc"), filepath.Join(binDir, "c++")
+}
+
+// GetPkgConfigPath returns the pkg-config command linked to got, which cgo
+// runs through PKG_CONFIG
+func GetPkgConfigPath(projectPath string) string {
+	name := "pkg-config"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(projectPath, depsDir, "bin", name)
 }
 
 func GetEnvConfigPath(projectPath string) string {
 	return filepath.Join(GetDepsDir(projectPath), "env.txt")
 }
 
-func SetBuildEnv(projectPath string) {
+// BuildEnv returns the environment variables for building the project with
+// cgo against its Python, with PATH prefixed to the current PATH
+func BuildEnv(projectPath string) map[string]string {
 	absPath, err := filepath.Abs(projectPath)
 	if err != nil {
 		panic(err)
 	}
+	python := GetPythonLayout(absPath)
 	path := os.Getenv("PATH")
 	path = GetGoBinDir(absPath) + pathSeparator() + path
-	path = GetPythonBinDir(absPath) + pathSeparator() + path
+	path = python.BinDir() + pathSeparator() + path
 	if runtime.GOOS == "windows" {
 		path = GetMingwRoot(absPath) + pathSeparator() + path
-		path = GetTinyPkgConfigDir(absPath) + pathSeparator() + path
 	}
-	os.Setenv("PATH", path)
-	os.Setenv("GOPATH", GetGoPath(absPath))
-	os.Setenv("GOROOT", GetGoRoot(absPath))
-	os.Setenv("GOCACHE", GetGoCacheDir(absPath))
-	os.Setenv("PKG_CONFIG_PATH", GetPythonPkgConfigDir(absPath))
-	os.Setenv("CGO_ENABLED", "1")
+	vars := map[string]string{
+		"PATH":            path,
+		"GOPATH":          GetGoPath(absPath),
+		"GOROOT":          GetGoRoot(absPath),
+		"GOCACHE":         GetGoCacheDir(absPath),
+		"PKG_CONFIG_PATH": python.PkgConfigDir,
+		"CGO_ENABLED":     "1",
+	}
+	if pkgConfig := GetPkgConfigPath(absPath); fileExists(pkgConfig) {
+		vars["PKG_CONFIG"] = pkgConfig
+	}
+	// Prefer the project's zig toolchain over the host compiler
+	if cc, cxx := GetZigCC(absPath); fileExists(cc) {
+		vars["CC"] = cc
+		vars["CXX"] = cxx
+	}
+	return vars
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// SetBuildEnv sets the build environment of the project, linking
+// .deps/bin/pkg-config to the running got so cgo uses its pkg-config
+func SetBuildEnv(projectPath string) {
+	if err := linkPkgConfig(projectPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: falling back to the pkg-config in PATH: %v\n", err)
+	}
+	for key, value := range BuildEnv(projectPath) {
+		os.Setenv(key, value)
+	}
 }
 
 func pathSeparator() string {
@@ -122,7 +166,7 @@ func WriteEnvFile(projectPath, pythonHome, pythonPath string) error {
 	envVars := []string{
 		fmt.Sprintf("PYTHONPATH=%s", strings.TrimSpace(pythonPath)),
 		fmt.Sprintf("PYTHONHOME=%s", pythonHome),
-		fmt.Sprintf("PATH=%s", GetPythonBinDir(projectPath)),
+		fmt.Sprintf("PATH=%s", pythonBinDir(pythonHome)),
 	}
 
 	// Write to env.txt
@@ -183,3 +227,43 @@ func ReadEnv(projectDir string) (map[string]string, error) {
 	}
 	return envs, nil
 }
+
+// linkPkgConfig points the project's pkg-config command at the running
+// executable, which acts as pkg-config when invoked under that name
+func linkPkgConfig(projectPath string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+	link := GetPkgConfigPath(projectPath)
+	if fi, err := os.Stat(link); err == nil {
+		if exeInfo, err := os.Stat(exe); err == nil && os.SameFile(fi, exeInfo) {
+			return nil
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(link), 0755); err != nil {
+		return err
+	}
+	if err := os.Remove(link); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if runtime.GOOS != "windows" {
+		return os.Symlink(exe, link)
+	}
+	// Symlinks need extra privileges on Windows, hard link or copy instead
+	if err := os.Link(exe, link); err == nil {
+		return nil
+	}
+	return copyFile(exe, link)
+}
+
+func copyFile(src, dst string) error {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, content, 0755)
+}
@@ -0,0 +1,77 @@
+package env
+
+import (
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	// PythonSourceStandalone is a python-build-standalone build installed in .deps/python
+	PythonSourceStandalone = "standalone"
+	// PythonSourceExternal is an existing interpreter such as a system or pyenv Python
+	PythonSourceExternal = "external"
+	// PythonSourceConda is the Python of a conda environment
+	PythonSourceConda = "conda"
+)
+
+// PythonLayout locates the parts of the project's Python installation
+type PythonLayout struct {
+	Home         string // PYTHONHOME of the installation
+	Executable   string // interpreter, empty to search Home
+	LibDir       string // directory containing libpython
+	PkgConfigDir string // directory containing python3-embed.pc
+}
+
+// GetPythonLayout returns the layout of the project's Python. It lives in
+// .deps/python unless the manifest records an external installation.
+func GetPythonLayout(projectPath string) PythonLayout {
+	layout := PythonLayout{
+		Home:         GetPythonRoot(projectPath),
+		LibDir:       GetPythonLibDir(projectPath),
+		PkgConfigDir: GetPythonPkgConfigDir(projectPath),
+	}
+	m, err := ReadManifest(projectPath)
+	if err != nil || m.Python.Home == "" {
+		return layout
+	}
+	layout.Home = m.Python.Home
+	layout.Executable = m.Python.Executable
+	if m.Python.LibDir != "" {
+		layout.LibDir = m.Python.LibDir
+	}
+	if m.Python.PkgConfigDir != "" {
+		layout.PkgConfigDir = m.Python.PkgConfigDir
+	}
+	return layout
+}
+
+// BinDir returns the directory containing the interpreter
+func (l PythonLayout) BinDir() string {
+	if l.Executable != "" {
+		return filepath.Dir(l.Executable)
+	}
+	return pythonBinDir(l.Home)
+}
+
+// PythonEnv returns the Python environment of the layout
+func (l PythonLayout) PythonEnv() *PythonEnv {
+	return &PythonEnv{Root: l.Home, Exe: l.Executable}
+}
+
+// Info describes the Python build of the layout, from its PYTHON.json or by
+// running the interpreter for installations without one
+func (l PythonLayout) Info() (*PythonInfo, error) {
+	pyEnv := l.PythonEnv()
+	if info, err := pyEnv.Info(); err == nil {
+		return info, nil
+	}
+	return pyEnv.ProbeInfo()
+}
+
+// pythonBinDir returns the binary directory of a Python installation
+func pythonBinDir(pythonHome string) string {
+	if runtime.GOOS == "windows" {
+		return pythonHome
+	}
+	return filepath.Join(pythonHome, "bin")
+}
@@ -0,0 +1,96 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// GoManifest records the installed Go toolchain
+type GoManifest struct {
+	Version string `json:"version"`
+}
+
+// PythonManifest records the installed Python build
+type PythonManifest struct {
+	Source       string `json:"source,omitempty"`
+	Version      string `json:"version"`
+	BuildDate    string `json:"build_date,omitempty"`
+	ABI          string `json:"abi,omitempty"`
+	FreeThreaded bool   `json:"free_threaded,omitempty"`
+	Debug        bool   `json:"debug,omitempty"`
+	TargetTriple string `json:"target_triple,omitempty"`
+	Libc         string `json:"libc,omitempty"`
+	Microarch    string `json:"microarch,omitempty"`
+	LinkMode     string `json:"link_mode,omitempty"`
+	LibName      string `json:"lib_name,omitempty"`
+	// Profile is the install profile of python-build-standalone builds and
+	// Excluded the files and directories of the install tree it left out
+	Profile  string   `json:"profile,omitempty"`
+	Excluded []string `json:"excluded,omitempty"`
+	// Locations of an installation outside .deps/python
+	Home         string `json:"home,omitempty"`
+	Executable   string `json:"executable,omitempty"`
+	LibDir       string `json:"lib_dir,omitempty"`
+	PkgConfigDir string `json:"pkg_config_dir,omitempty"`
+}
+
+// ZigManifest records the zig C toolchain used as CC
+type ZigManifest struct {
+	Version string `json:"version"`
+	Target  string `json:"target,omitempty"`
+}
+
+// Manifest records how the dependencies in .deps were installed
+type Manifest struct {
+	Go     GoManifest     `json:"go"`
+	Python PythonManifest `json:"python"`
+	Zig    *ZigManifest   `json:"zig,omitempty"`
+}
+
+// NewPythonManifest records the build described by info
+func NewPythonManifest(info *PythonInfo, buildDate string) PythonManifest {
+	return PythonManifest{
+		Source:       PythonSourceStandalone,
+		Version:      info.Version,
+		BuildDate:    buildDate,
+		ABI:          info.ABI(),
+		FreeThreaded: info.FreeThreaded(),
+		Debug:        info.Debug(),
+		TargetTriple: info.TargetTriple,
+		LinkMode:     info.LinkMode,
+		LibName:      info.LibName(),
+	}
+}
+
+// GetManifestPath returns the path of the dependency manifest relative to project path
+func GetManifestPath(projectPath string) string {
+	return filepath.Join(GetDepsDir(projectPath), "manifest.json")
+}
+
+// ReadManifest loads .deps/manifest.json
+func ReadManifest(projectPath string) (*Manifest, error) {
+	path := GetManifestPath(projectPath)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %v", path, err)
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(content, m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %v", path, err)
+	}
+	return m, nil
+}
+
+// WriteManifest writes .deps/manifest.json
+func WriteManifest(projectPath string, m *Manifest) error {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %v", err)
+	}
+	if err := os.WriteFile(GetManifestPath(projectPath), append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %v", err)
+	}
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 // PythonEnv represents a Python environment
 type PythonEnv struct {
 	Root string // Root directory of the Python installation
+	Exe  string // Interpreter path, empty to look it up in Root
 }
 
 // NewPythonEnv creates a new Python environment instance
@@ -23,8 +24,23 @@ func NewPythonEnv(pythonHome string) *PythonEnv {
 	}
 }
 
-// Python returns the path to the Python executable
+// Python returns the path to the Python executable. The interpreter recorded
+// in PYTHON.json is preferred, falling back to scanning the bin directory.
 func (e *PythonEnv) Python() (string, error) {
+	if e.Exe != "" {
+		if _, err := os.Stat(e.Exe); err != nil {
+			return "", fmt.Errorf("python executable not found: %v", err)
+		}
+		return e.Exe, nil
+	}
+	if info, err := e.Info(); err == nil {
+		if exe := info.Path(e.Root, info.Exe); exe != "" {
+			if _, err := os.Stat(exe); err == nil {
+				return exe, nil
+			}
+		}
+	}
+
 	binDir := e.Root
 	if runtime.GOOS != "windows" {
 		binDir = filepath.Join(e.Root, "bin")
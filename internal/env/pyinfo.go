@@ -0,0 +1,188 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	// PythonInfoFile is the build description shipped by python-build-standalone,
+	// stored in the Python root after extraction
+	PythonInfoFile = "PYTHON.json"
+	// installPrefix is the directory PYTHON.json paths are relative to
+	installPrefix = "install/"
+)
+
+// PythonLink is a library libpython links against
+type PythonLink struct {
+	Name       string `json:"name"`
+	PathStatic string `json:"path_static,omitempty"`
+	System     bool   `json:"system,omitempty"`
+	Framework  bool   `json:"framework,omitempty"`
+}
+
+// PythonExtension is a variant of an extension module of the build
+type PythonExtension struct {
+	InCore bool         `json:"in_core"`
+	Links  []PythonLink `json:"links"`
+}
+
+// PythonInfo describes a Python build. It mirrors the fields of
+// python-build-standalone's PYTHON.json that got relies on.
+type PythonInfo struct {
+	Version       string         `json:"python_version"`
+	MajorMinor    string         `json:"python_major_minor_version"`
+	Tag           string         `json:"python_tag"`
+	ABITag        string         `json:"python_abi_tag"`
+	Exe           string         `json:"python_exe"`
+	TargetTriple  string         `json:"target_triple"`
+	Optimizations string         `json:"optimizations"`
+	LinkMode      string         `json:"libpython_link_mode"`
+	ConfigVars    map[string]any `json:"python_config_vars"`
+	BuildInfo     struct {
+		Core struct {
+			SharedLib string       `json:"shared_lib"`
+			StaticLib string       `json:"static_lib"`
+			Links     []PythonLink `json:"links"`
+		} `json:"core"`
+		Extensions map[string][]PythonExtension `json:"extensions"`
+	} `json:"build_info"`
+}
+
+// ConfigVar returns a sysconfig variable recorded for the build
+func (i *PythonInfo) ConfigVar(name string) string {
+	v, ok := i.ConfigVars[name]
+	if !ok || v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// ABI returns the ABI flags of the build, e.g. "t" for free-threaded builds
+func (i *PythonInfo) ABI() string {
+	if i.ABITag != "" {
+		return i.ABITag
+	}
+	return i.ConfigVar("ABIFLAGS")
+}
+
+// FreeThreaded reports whether the build has the GIL disabled
+func (i *PythonInfo) FreeThreaded() bool {
+	return strings.Contains(i.ABI(), "t") || i.ConfigVar("Py_GIL_DISABLED") == "1"
+}
+
+// Debug reports whether the build is a debug build
+func (i *PythonInfo) Debug() bool {
+	return strings.Contains(i.ABI(), "d") || i.ConfigVar("Py_DEBUG") == "1"
+}
+
+// Variant returns the build variant, e.g. "freethreaded+pgo" as named by
+// python-build-standalone, or one derived from the ABI for other builds
+func (i *PythonInfo) Variant() string {
+	if i.Optimizations != "" {
+		return i.Optimizations
+	}
+	var parts []string
+	if i.FreeThreaded() {
+		parts = append(parts, "freethreaded")
+	}
+	if i.Debug() {
+		parts = append(parts, "debug")
+	}
+	if len(parts) == 0 {
+		return "default"
+	}
+	return strings.Join(parts, "+")
+}
+
+// LibName returns the name of libpython as passed to -l, e.g. "python3.13t",
+// or "python313t" on Windows
+func (i *PythonInfo) LibName() string {
+	if i.windows() {
+		return "python" + strings.ReplaceAll(i.MajorMinor, ".", "") + i.ABI()
+	}
+	return "python" + i.MajorMinor + i.ABI()
+}
+
+// windows reports whether the build targets Windows
+func (i *PythonInfo) windows() bool {
+	if i.TargetTriple == "" {
+		return runtime.GOOS == "windows"
+	}
+	return strings.Contains(i.TargetTriple, "windows") || strings.HasPrefix(i.TargetTriple, "win-")
+}
+
+// Path resolves a path recorded in PYTHON.json against the Python root.
+// PYTHON.json paths are relative to the archive's python/ directory and only
+// the install/ tree is extracted, so other paths resolve to an empty string.
+func (i *PythonInfo) Path(root, p string) string {
+	if p == "" {
+		return ""
+	}
+	if filepath.IsAbs(p) {
+		return p
+	}
+	if !strings.HasPrefix(p, installPrefix) {
+		return ""
+	}
+	return filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(p, installPrefix)))
+}
+
+// Info reads the build description stored in the Python root
+func (e *PythonEnv) Info() (*PythonInfo, error) {
+	path := filepath.Join(e.Root, PythonInfoFile)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	info := &PythonInfo{}
+	if err := json.Unmarshal(content, info); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return info, nil
+}
+
+// probeInfoScript prints a PYTHON.json compatible description of the running interpreter
+const probeInfoScript = `
+import json, os, platform, sys, sysconfig
+config = sysconfig.get_config_vars()
+abi = getattr(sys, "abiflags", "")
+if not abi and config.get("Py_GIL_DISABLED"):
+    abi = "t"
+keys = ["ABIFLAGS", "INCLUDEPY", "LDLIBRARY", "LIBDIR", "LIBPC", "LIBPL", "LIBRARY", "LIBS", "MODLIBS", "SYSLIBS",
+        "Py_ENABLE_SHARED", "Py_GIL_DISABLED", "Py_DEBUG", "VERSION", "installed_base", "prefix", "exec_prefix"]
+shared = os.name == "nt" or bool(config.get("Py_ENABLE_SHARED"))
+print(json.dumps({
+    "python_version": platform.python_version(),
+    "python_major_minor_version": "%d.%d" % sys.version_info[:2],
+    "python_tag": sys.implementation.cache_tag.replace("cpython-", "cp"),
+    "python_abi_tag": abi,
+    "python_exe": os.path.abspath(sys.executable),
+    "target_triple": sysconfig.get_platform(),
+    "libpython_link_mode": "shared" if shared else "static",
+    "python_config_vars": {k: config[k] for k in keys if config.get(k) is not None},
+}))
+`
+
+// ProbeInfo describes the interpreter by running it, for installations that
+// don't ship a PYTHON.json
+func (e *PythonEnv) ProbeInfo() (*PythonInfo, error) {
+	pythonBin, err := e.Python()
+	if err != nil {
+		return nil, err
+	}
+	output, err := exec.Command(pythonBin, "-c", probeInfoScript).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Python info: %v", err)
+	}
+	info := &PythonInfo{}
+	if err := json.Unmarshal(output, info); err != nil {
+		return nil, fmt.Errorf("unexpected Python info output: %v", err)
+	}
+	return info, nil
+}
@@ -0,0 +1,90 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+const pythonInfoFixture = `{
+  "version": "7",
+  "target_triple": "x86_64-unknown-linux-gnu",
+  "optimizations": "freethreaded+pgo",
+  "python_tag": "cp313",
+  "python_abi_tag": "t",
+  "python_exe": "install/bin/python3.13t",
+  "python_major_minor_version": "3.13",
+  "python_version": "3.13.0",
+  "libpython_link_mode": "shared",
+  "python_config_vars": {"ABIFLAGS": "t", "Py_GIL_DISABLED": 1, "LDLIBRARY": "libpython3.13t.so"},
+  "build_info": {
+    "core": {
+      "shared_lib": "install/lib/libpython3.13t.so.1.0",
+      "static_lib": "build/lib/libpython3.13t.a",
+      "links": [{"name": "dl", "system": true}, {"name": "pthread", "system": true}]
+    }
+  }
+}`
+
+func TestPythonInfo(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, PythonInfoFile), []byte(pythonInfoFixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pyEnv := NewPythonEnv(root)
+	info, err := pyEnv.Info()
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+
+	if info.Version != "3.13.0" || info.MajorMinor != "3.13" {
+		t.Errorf("Info() version = %s (%s), want 3.13.0 (3.13)", info.Version, info.MajorMinor)
+	}
+	if !info.FreeThreaded() {
+		t.Error("FreeThreaded() = false, want true")
+	}
+	if info.Debug() {
+		t.Error("Debug() = true, want false")
+	}
+	if got := info.Variant(); got != "freethreaded+pgo" {
+		t.Errorf("Variant() = %s, want freethreaded+pgo", got)
+	}
+	if got := (&PythonInfo{ABITag: "td"}).Variant(); got != "freethreaded+debug" {
+		t.Errorf("Variant() of a probed build = %s, want freethreaded+debug", got)
+	}
+	if got := info.LibName(); got != "python3.13t" {
+		t.Errorf("LibName() = %s, want python3.13t", got)
+	}
+	if got := info.ConfigVar("Py_GIL_DISABLED"); got != "1" {
+		t.Errorf("ConfigVar(Py_GIL_DISABLED) = %q, want 1", got)
+	}
+	if got, want := info.Path(root, info.BuildInfo.Core.SharedLib), filepath.Join(root, "lib", "libpython3.13t.so.1.0"); got != want {
+		t.Errorf("Path(shared_lib) = %s, want %s", got, want)
+	}
+	if got := info.Path(root, info.BuildInfo.Core.StaticLib); got != "" {
+		t.Errorf("Path(static_lib) = %s, want empty for paths outside install/", got)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	// The interpreter named in PYTHON.json wins over other matching names
+	binDir := filepath.Join(root, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"python3", "python3.13t"} {
+		if err := os.WriteFile(filepath.Join(binDir, name), nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := pyEnv.Python()
+	if err != nil {
+		t.Fatalf("Python() error = %v", err)
+	}
+	if want := filepath.Join(binDir, "python3.13t"); got != want {
+		t.Errorf("Python() = %s, want %s", got, want)
+	}
+}
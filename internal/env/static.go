@@ -0,0 +1,147 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// pyBuildDir holds the static libraries of python-build-standalone's
+	// build/lib directory, extracted when linking libpython statically
+	pyBuildDir = "python-build"
+	// archiveBuildLibPrefix is the prefix of static library paths in PYTHON.json
+	archiveBuildLibPrefix = "build/lib/"
+)
+
+// GetPythonBuildDir returns the directory for linking libpython statically
+func GetPythonBuildDir(projectPath string) string {
+	return filepath.Join(GetDepsDir(projectPath), pyBuildDir)
+}
+
+// GetPythonBuildLibDir returns the directory of the extracted static libraries
+func GetPythonBuildLibDir(projectPath string) string {
+	return filepath.Join(GetPythonBuildDir(projectPath), "lib")
+}
+
+// GetPythonStaticPkgConfigDir returns the directory of the pkg-config files
+// linking libpython statically
+func GetPythonStaticPkgConfigDir(projectPath string) string {
+	return filepath.Join(GetPythonBuildDir(projectPath), "pkgconfig")
+}
+
+// IncludeDir returns the directory containing Python.h of the build described by info
+func (l PythonLayout) IncludeDir(info *PythonInfo) string {
+	includeDir := filepath.Join(l.Home, "include")
+	if !info.windows() {
+		includeDir = filepath.Join(includeDir, "python"+info.MajorMinor+info.ABI())
+	}
+	// Interpreters not installed by got record their own include directory;
+	// python-build-standalone records its build prefix, which doesn't exist
+	if includePy := info.ConfigVar("INCLUDEPY"); includePy != "" && !dirExists(includeDir) && dirExists(includePy) {
+		includeDir = includePy
+	}
+	return includeDir
+}
+
+// StaticLibPython returns the path of the static libpython of the build
+// described by info, looking in buildLibDir for python-build-standalone's
+// build/lib files, or an empty string if there is none
+func StaticLibPython(layout PythonLayout, info *PythonInfo, buildLibDir string) string {
+	name := "lib" + info.LibName() + ".a"
+	var candidates []string
+	if lib := info.BuildInfo.Core.StaticLib; lib != "" {
+		candidates = append(candidates, resolveBuildLib(layout.Home, info, buildLibDir, lib))
+	}
+	if libPL := info.ConfigVar("LIBPL"); libPL != "" {
+		candidates = append(candidates, filepath.Join(libPL, name))
+	}
+	candidates = append(candidates, filepath.Join(buildLibDir, name))
+	// CPython installs the static library into the config directory
+	configDirs, _ := filepath.Glob(filepath.Join(layout.Home, "lib", "python"+info.MajorMinor+info.ABI(), "config-*"))
+	sort.Strings(configDirs)
+	for _, dir := range configDirs {
+		candidates = append(candidates, filepath.Join(dir, name))
+	}
+	candidates = append(candidates, filepath.Join(layout.LibDir, name))
+
+	for _, path := range candidates {
+		if path != "" && fileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// StaticLinkFlags returns the linker flags for the libraries a static
+// libpython depends on. Libraries python-build-standalone built itself are
+// linked from buildLibDir, or left out if it is empty.
+func (i *PythonInfo) StaticLinkFlags(buildLibDir string) []string {
+	links := append([]PythonLink{}, i.BuildInfo.Core.Links...)
+	names := make([]string, 0, len(i.BuildInfo.Extensions))
+	for name := range i.BuildInfo.Extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// The first variant is the one built
+		if variants := i.BuildInfo.Extensions[name]; len(variants) > 0 && variants[0].InCore {
+			links = append(links, variants[0].Links...)
+		}
+	}
+
+	var flags []string
+	seen := map[string]bool{}
+	for _, link := range links {
+		var linkFlags []string
+		switch {
+		case link.Framework:
+			linkFlags = []string{"-framework", link.Name}
+		case link.System:
+			linkFlags = []string{"-l" + link.Name}
+		case buildLibDir != "":
+			linkFlags = []string{"-l" + link.Name}
+			if path := resolveBuildLib("", i, buildLibDir, link.PathStatic); path != "" && fileExists(path) {
+				linkFlags = []string{path}
+			}
+		default:
+			// Without the build libraries only system libraries can be linked
+			continue
+		}
+		key := strings.Join(linkFlags, " ")
+		if !seen[key] {
+			seen[key] = true
+			flags = append(flags, linkFlags...)
+		}
+	}
+	if len(links) == 0 {
+		// Probed interpreters only record sysconfig's library variables
+		for _, name := range []string{"LIBS", "SYSLIBS", "MODLIBS"} {
+			for _, flag := range strings.Fields(i.ConfigVar(name)) {
+				if !seen[flag] {
+					seen[flag] = true
+					flags = append(flags, flag)
+				}
+			}
+		}
+	}
+	return flags
+}
+
+// resolveBuildLib resolves a python-build-standalone library path such as
+// "build/lib/libz.a" against buildLibDir, or an install/ path against home
+func resolveBuildLib(home string, info *PythonInfo, buildLibDir, p string) string {
+	if rest, ok := strings.CutPrefix(p, archiveBuildLibPrefix); ok {
+		return filepath.Join(buildLibDir, filepath.FromSlash(rest))
+	}
+	if home == "" {
+		return ""
+	}
+	return info.Path(home, p)
+}
+
+func dirExists(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
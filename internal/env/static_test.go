@@ -0,0 +1,93 @@
+package env
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const staticInfoFixture = `{
+  "python_major_minor_version": "3.13",
+  "python_version": "3.13.0",
+  "target_triple": "x86_64-unknown-linux-gnu",
+  "libpython_link_mode": "shared",
+  "build_info": {
+    "core": {
+      "static_lib": "build/lib/libpython3.13.a",
+      "links": [
+        {"name": "dl", "system": true},
+        {"name": "ffi", "path_static": "build/lib/libffi.a"},
+        {"name": "CoreFoundation", "framework": true}
+      ]
+    },
+    "extensions": {
+      "_ssl": [{"in_core": true, "links": [{"name": "ssl", "path_static": "build/lib/libssl.a"}, {"name": "dl", "system": true}]}],
+      "_tkinter": [{"in_core": false, "links": [{"name": "tcl", "path_static": "build/lib/libtcl.a"}]}]
+    }
+  }
+}`
+
+func TestStaticLibPython(t *testing.T) {
+	home := t.TempDir()
+	buildLibDir := filepath.Join(t.TempDir(), "lib")
+	if err := os.WriteFile(filepath.Join(home, PythonInfoFile), []byte(staticInfoFixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := NewPythonEnv(home).Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout := PythonLayout{Home: home, LibDir: filepath.Join(home, "lib")}
+
+	if got := StaticLibPython(layout, info, buildLibDir); got != "" {
+		t.Errorf("StaticLibPython() = %q before extraction, want none", got)
+	}
+	configLib := filepath.Join(home, "lib", "python3.13", "config-3.13-x86_64-linux-gnu", "libpython3.13.a")
+	writeFile(t, configLib)
+	if got := StaticLibPython(layout, info, buildLibDir); got != configLib {
+		t.Errorf("StaticLibPython() = %q, want %q", got, configLib)
+	}
+	buildLib := filepath.Join(buildLibDir, "libpython3.13.a")
+	writeFile(t, buildLib)
+	if got := StaticLibPython(layout, info, buildLibDir); got != buildLib {
+		t.Errorf("StaticLibPython() = %q, want %q", got, buildLib)
+	}
+}
+
+func TestStaticLinkFlags(t *testing.T) {
+	buildLibDir := t.TempDir()
+	info := &PythonInfo{}
+	if err := json.Unmarshal([]byte(staticInfoFixture), info); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(buildLibDir, "libffi.a"))
+
+	got := info.StaticLinkFlags(buildLibDir)
+	want := []string{"-ldl", filepath.Join(buildLibDir, "libffi.a"), "-framework", "CoreFoundation", "-lssl"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("StaticLinkFlags(buildLibDir) = %q, want %q", got, want)
+	}
+	got = info.StaticLinkFlags("")
+	want = []string{"-ldl", "-framework", "CoreFoundation"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("StaticLinkFlags(\"\") = %q, want %q", got, want)
+	}
+
+	probed := &PythonInfo{ConfigVars: map[string]any{"LIBS": "-ldl", "SYSLIBS": "-lm", "MODLIBS": "-lm -lz"}}
+	want = []string{"-ldl", "-lm", "-lz"}
+	if got := probed.StaticLinkFlags(""); !reflect.DeepEqual(got, want) {
+		t.Errorf("StaticLinkFlags() of probed build = %q, want %q", got, want)
+	}
+}
+
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,57 @@
+package env
+
+import (
+	"path/filepath"
+)
+
+// targetsDir is the directory for the Python builds of cross-compilation targets
+const targetsDir = "targets"
+
+// GetTargetDir returns the sysroot holding the Python build for goos/goarch.
+// Python is installed in its install/ directory so that the /install prefix
+// of the python-build-standalone pkg-config files resolves against
+// PKG_CONFIG_SYSROOT_DIR.
+func GetTargetDir(projectPath, goos, goarch string) string {
+	return filepath.Join(projectPath, depsDir, targetsDir, goos+"-"+goarch)
+}
+
+// GetTargetPythonRoot returns the Python installation root of a target sysroot
+func GetTargetPythonRoot(projectPath, goos, goarch string) string {
+	return filepath.Join(GetTargetDir(projectPath, goos, goarch), "install")
+}
+
+// GetTargetSourcePath returns the file recording the archive URL the
+// target's Python was installed from
+func GetTargetSourcePath(projectPath, goos, goarch string) string {
+	return filepath.Join(GetTargetDir(projectPath, goos, goarch), "source.url")
+}
+
+// GetTargetLibDir returns the directory containing the target's libpython
+func GetTargetLibDir(projectPath, goos, goarch string) string {
+	return filepath.Join(GetTargetPythonRoot(projectPath, goos, goarch), "lib")
+}
+
+// GetTargetPkgConfigDir returns the pkg-config directory of a target sysroot
+func GetTargetPkgConfigDir(projectPath, goos, goarch string) string {
+	return filepath.Join(GetTargetLibDir(projectPath, goos, goarch), "pkgconfig")
+}
+
+// TargetBuildEnv returns the environment variables for cross-compiling the
+// project for goos/goarch with the C and C++ compilers cc and cxx, on top of BuildEnv
+func TargetBuildEnv(projectPath, goos, goarch, cc, cxx string) map[string]string {
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		panic(err)
+	}
+	vars := BuildEnv(absPath)
+	pkgConfigDir := GetTargetPkgConfigDir(absPath, goos, goarch)
+	vars["GOOS"] = goos
+	vars["GOARCH"] = goarch
+	vars["CC"] = cc
+	vars["CXX"] = cxx
+	vars["PKG_CONFIG_PATH"] = pkgConfigDir
+	// Keep pkg-config from falling back to the host's python3-embed
+	vars["PKG_CONFIG_LIBDIR"] = pkgConfigDir
+	vars["PKG_CONFIG_SYSROOT_DIR"] = GetTargetDir(absPath, goos, goarch)
+	return vars
+}